@@ -0,0 +1,41 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/dsh2dsh/fio/internal/app"
+)
+
+var anomaliesCmd = &cobra.Command{
+	Use:   "anomalies [input.csv...]",
+	Short: "Show unusually large payments",
+	Long: `It compares every payment with usual payments of its item, or of its section,
+if the item has not enough payments, and shows payments, which are much larger
+than usual. All payments from input files are used as history, but only
+payments from the period, given by --month or --from-date and --to-date, are
+shown.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		from, to, err := parsePeriod(oneMonth, fromDate, toDate)
+		cobra.CheckErr(err)
+
+		report := withDirection(app.NewReport(cfg))
+		anomalies := app.NewAnomalies(cfg)
+		withInputFiles(args, func(src app.Source) error {
+			return report.Each(src, anomalies.Add)
+		})
+
+		found := anomalies.Detect(from, to)
+		if len(found) == 0 {
+			fmt.Fprintln(os.Stderr, "No anomalies found.")
+			return
+		}
+		cobra.CheckErr(app.WriteAnomalies(os.Stdout, found))
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(anomaliesCmd)
+}
@@ -0,0 +1,245 @@
+package cmd
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/dsh2dsh/fio/internal/app"
+)
+
+var errQuit = errors.New("quit")
+
+var categorizeCmd = &cobra.Command{
+	Use:   "categorize [input.csv...]",
+	Short: "Create rules for payments, which don't match any section",
+	Long: `It walks through payments, which don't match any rule, one by one, and asks
+for a section, existing or new one, and a criterion: account, account and VS,
+or regexp on note. The rule is appended to the config file, keeping its
+comments, and next payments, which match the new rule, are skipped.
+
+It's a line-based prompt: answers are lines read from stdin, so input files or
+--store are required.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) == 0 && !fromStore {
+			cobra.CheckErr(errors.New("input files or --store required"))
+		}
+
+		var unmatched []app.Record
+		report := newReport()
+		withInputFiles(args, func(src app.Source) error {
+			return report.Each(src, func(rec app.Record) error {
+				if m, err := cfg.MatchSection(rec); err != nil {
+					return err
+				} else if !m.Found() {
+					unmatched = append(unmatched, rec)
+				}
+				return nil
+			})
+		})
+
+		c := &categorizer{in: bufio.NewReader(os.Stdin), out: os.Stdout}
+		err := c.Run(unmatched)
+		if errors.Is(err, errQuit) {
+			err = nil
+		}
+		fmt.Fprintf(os.Stderr, "%d rules added, %d payments resolved by them.\n",
+			c.added, c.resolved)
+		cobra.CheckErr(err)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(categorizeCmd)
+}
+
+type categorizer struct {
+	in  *bufio.Reader
+	out io.Writer
+
+	added    int
+	resolved int
+}
+
+func (self *categorizer) Run(records []app.Record) error {
+	for i, rec := range records {
+		if m, err := cfg.MatchSection(rec); err != nil {
+			return err
+		} else if m.Found() {
+			continue // resolved by one of added rules
+		}
+
+		fmt.Fprintf(self.out, "\n[%d/%d] %s\n", i+1, len(records), rec.String())
+		if rec.AccountId() != "" {
+			fmt.Fprintf(self.out, "  account: %s, VS: %s\n", rec.AccountId(),
+				rec.Vs())
+		}
+
+		sectName, err := self.askSection(rec)
+		if err != nil {
+			return err
+		} else if sectName == "" {
+			continue // skipped
+		}
+
+		rule, err := self.askRule(rec)
+		if err != nil {
+			return err
+		}
+
+		// payments, which the new rule can resolve
+		pending, err := unmatchedRecords(records[i+1:])
+		if err != nil {
+			return err
+		}
+
+		if err := cfg.AddRule(sectName, !rec.Out(), rule); err != nil {
+			return err
+		} else if err := app.AppendRule(cfgPath, sectName, !rec.Out(), rule); err != nil {
+			return err
+		}
+		self.added++
+
+		stillUnmatched, err := unmatchedRecords(pending)
+		if err != nil {
+			return err
+		}
+		resolved := 1 + len(pending) - len(stillUnmatched)
+		self.resolved += resolved
+		fmt.Fprintf(self.out, "Rule added to section %q, it matches %d payments.\n",
+			sectName, resolved)
+	}
+	return nil
+}
+
+// unmatchedRecords returns records, which don't match any section.
+func unmatchedRecords(records []app.Record) ([]app.Record, error) {
+	var unmatched []app.Record
+	for _, rec := range records {
+		if m, err := cfg.MatchSection(rec); err != nil {
+			return nil, err
+		} else if !m.Found() {
+			unmatched = append(unmatched, rec)
+		}
+	}
+	return unmatched, nil
+}
+
+// askSection returns name of chosen section or empty string, if the payment is
+// skipped.
+func (self *categorizer) askSection(rec app.Record) (string, error) {
+	var sections []*app.SectionConfig
+	_ = cfg.WalkSections(func(sect *app.SectionConfig) error {
+		if cfg.CheckRuleSection(sect.Name, !rec.Out()) == nil {
+			sections = append(sections, sect)
+		}
+		return nil
+	})
+
+	fmt.Fprintln(self.out, "Section:")
+	for i, sect := range sections {
+		fmt.Fprintf(self.out, "  %d) %s\n", i+1, sect.Path())
+	}
+	fmt.Fprintln(self.out, "  n) new section, s) skip, q) quit")
+
+	for {
+		answer, err := self.ask("> ")
+		if err != nil {
+			return "", err
+		}
+
+		switch answer {
+		case "q":
+			return "", errQuit
+		case "s":
+			return "", nil
+		case "n":
+			return self.askNewSection(rec)
+		}
+
+		if n, err := strconv.Atoi(answer); err == nil && n > 0 && n <= len(sections) {
+			return sections[n-1].Name, nil
+		}
+	}
+}
+
+func (self *categorizer) askNewSection(rec app.Record) (string, error) {
+	for {
+		name, err := self.ask("Name of new section: ")
+		if err != nil {
+			return "", err
+		} else if name == "" {
+			continue
+		} else if err := cfg.CheckRuleSection(name, !rec.Out()); err != nil {
+			fmt.Fprintln(self.out, err)
+			continue
+		}
+		return name, nil
+	}
+}
+
+func (self *categorizer) askRule(rec app.Record) (*app.SectionRule, error) {
+	fmt.Fprintln(self.out, "Match by:")
+	if rec.AccountId() != "" {
+		fmt.Fprintf(self.out, "  1) account %s\n", rec.AccountId())
+		if rec.Vs() != "" {
+			fmt.Fprintf(self.out, "  2) account %s and VS %s\n", rec.AccountId(),
+				rec.Vs())
+		}
+	}
+	fmt.Fprintln(self.out, "  3) regexp on note")
+
+	for {
+		answer, err := self.ask("> ")
+		if err != nil {
+			return nil, err
+		}
+
+		switch {
+		case answer == "1" && rec.AccountId() != "":
+			return &app.SectionRule{Account: rec.AccountId()}, nil
+		case answer == "2" && rec.AccountId() != "" && rec.Vs() != "":
+			return &app.SectionRule{Account: rec.AccountId(), Vs: rec.Vs()}, nil
+		case answer == "3":
+			return self.askRe(rec)
+		}
+	}
+}
+
+func (self *categorizer) askRe(rec app.Record) (*app.SectionRule, error) {
+	def := app.NoteRe(rec.Note())
+	for {
+		re, err := self.ask(fmt.Sprintf("Regexp [%s]: ", def))
+		if err != nil {
+			return nil, err
+		} else if re == "" {
+			re = def
+		}
+
+		if _, err := regexp.Compile(re); err != nil {
+			fmt.Fprintln(self.out, err)
+		} else {
+			return &app.SectionRule{Re: re}, nil
+		}
+	}
+}
+
+// ask prints prompt and returns trimmed answer. End of input is errQuit.
+func (self *categorizer) ask(prompt string) (string, error) {
+	fmt.Fprint(self.out, prompt)
+	line, err := self.in.ReadString('\n')
+	if errors.Is(err, io.EOF) && line == "" {
+		fmt.Fprintln(self.out)
+		return "", errQuit
+	} else if err != nil && !errors.Is(err, io.EOF) {
+		return "", fmt.Errorf("read answer: %w", err)
+	}
+	return strings.TrimSpace(line), nil
+}
@@ -0,0 +1,90 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/dsh2dsh/fio/internal/app"
+)
+
+var (
+	withMonth    string
+	withFromDate string
+	withToDate   string
+
+	compareCmd = &cobra.Command{
+		Use:   "compare [input.csv...]",
+		Short: "Compare report with previous period",
+		Long: `It compares sections and their items of the current period, given by --month
+or --from-date and --to-date, with a base period, given by --with-month or
+--with-from-date and --with-to-date, and shows difference of money in absolute
+and relative terms. New and disappeared items are marked.
+
+Without base period it compares with the previous period of the same length:
+previous month for --month or previous months for whole months, like the same
+months of the previous year.`,
+		Run: func(cmd *cobra.Command, args []string) {
+			from, to, err := parsePeriod(oneMonth, fromDate, toDate)
+			cobra.CheckErr(err)
+			baseFrom, baseTo, err := basePeriod(from, to)
+			cobra.CheckErr(err)
+
+			cur := withDirection(withPeriod(app.NewReport(cfg), from, to))
+			base := withDirection(withPeriod(app.NewReport(cfg), baseFrom, baseTo))
+			withInputFiles(args, func(src app.Source) error {
+				records, err := app.ReadAll(src)
+				if err != nil {
+					return err
+				} else if err := base.Parse(app.NewRecords(records)); err != nil {
+					return err
+				}
+				return cur.Parse(app.NewRecords(records))
+			})
+			printDuplicates(cur)
+			printUnmatched(cur)
+
+			if cur.Data().Empty() && base.Data().Empty() {
+				fmt.Fprintln(os.Stderr, "Nothing found for given dates.")
+				return
+			}
+			comparison := app.NewComparison(base.Data(), cur.Data())
+			cobra.CheckErr(comparison.WriteText(os.Stdout))
+		},
+	}
+)
+
+func init() {
+	rootCmd.AddCommand(compareCmd)
+
+	compareCmd.Flags().StringVar(&withMonth, "with-month", "",
+		"compare with given month (in format YYYY-MM)")
+	compareCmd.Flags().StringVar(&withFromDate, "with-from-date", "",
+		"compare with period from given date (in format YYYY-MM-DD)")
+	compareCmd.Flags().StringVar(&withToDate, "with-to-date", "",
+		"compare with period till given date (in format YYYY-MM-DD)")
+	compareCmd.MarkFlagsMutuallyExclusive("with-month", "with-from-date")
+	compareCmd.MarkFlagsMutuallyExclusive("with-month", "with-to-date")
+}
+
+// basePeriod returns base period from flags or period before from and to dates.
+func basePeriod(from, to time.Time) (time.Time, time.Time, error) {
+	if withMonth != "" || withFromDate != "" || withToDate != "" {
+		return parsePeriod(withMonth, withFromDate, withToDate)
+	} else if from.IsZero() || to.IsZero() {
+		return from, to, errors.New(
+			"base period required: current period isn't limited by dates")
+	}
+
+	// whole months
+	if from.Day() == 1 && to.AddDate(0, 0, 1).Day() == 1 {
+		months := (to.Year()-from.Year())*12 + int(to.Month()-from.Month()) + 1
+		return from.AddDate(0, -months, 0), from.AddDate(0, 0, -1), nil
+	}
+
+	days := int(to.Sub(from).Hours()/24) + 1
+	return from.AddDate(0, 0, -days), from.AddDate(0, 0, -1), nil
+}
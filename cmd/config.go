@@ -0,0 +1,58 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/dsh2dsh/fio/internal/app"
+)
+
+var (
+	configCmd = &cobra.Command{
+		Use:   "config",
+		Short: "Work with config file",
+	}
+
+	configCheckCmd = &cobra.Command{
+		Use:   "check [input.csv...]",
+		Short: "Check config file for problems",
+		Long: `It checks config file for problems, which don't prevent loading of it, but make
+it work not as expected: rules shadowed by rules before them, vs without
+account, unknown keys, unknown fields of payment in templates of rules and
+template of text report, which can't be parsed.
+
+With input files or --store it also lists rules, which never matched any
+payment. Payments of both directions are checked, unless --direction given.`,
+		Run: func(cmd *cobra.Command, args []string) {
+			checker := app.NewConfigChecker(cfg, cfgPath)
+			if len(args) > 0 || fromStore {
+				report := newReport()
+				if !cmd.Flags().Changed("direction") {
+					report = report.WithDirection(app.DirectionBoth)
+				}
+				withInputFiles(args, func(src app.Source) error {
+					return report.Each(src, checker.Add)
+				})
+			}
+
+			problems, err := checker.Check()
+			cobra.CheckErr(err)
+			if len(problems) == 0 {
+				fmt.Fprintln(os.Stderr, "No problems found.")
+				return
+			}
+
+			for _, p := range problems {
+				fmt.Println(p)
+			}
+			cobra.CheckErr(fmt.Errorf("problems found: %d", len(problems)))
+		},
+	}
+)
+
+func init() {
+	rootCmd.AddCommand(configCmd)
+	configCmd.AddCommand(configCheckCmd)
+}
@@ -0,0 +1,83 @@
+package cmd
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/dsh2dsh/fio/internal/app"
+)
+
+var (
+	explainLine int
+	explainNote string
+
+	explainCmd = &cobra.Command{
+		Use:   "explain [input.csv...]",
+		Short: "Show which rule matched every payment",
+		Long: `For every payment it shows the CSV line, date, amount and note, followed by
+the section, index of the matched rule inside of the section, criteria of this
+rule and the resulting key.`,
+		Run: func(cmd *cobra.Command, args []string) {
+			var noteRe *regexp.Regexp
+			if explainNote != "" {
+				re, err := regexp.Compile("(?i)" + explainNote)
+				cobra.CheckErr(err)
+				noteRe = re
+			}
+
+			report := newReport()
+			withInputFiles(args, func(src app.Source) error {
+				return report.Each(src, func(rec app.Record) error {
+					if explainLine > 0 && rec.Line() != explainLine {
+						return nil
+					} else if noteRe != nil && !noteRe.MatchString(rec.Note()) {
+						return nil
+					}
+					return explainRecord(rec)
+				})
+			})
+		},
+	}
+)
+
+func init() {
+	rootCmd.AddCommand(explainCmd)
+
+	explainCmd.Flags().IntVar(&explainLine, "line", 0,
+		"explain payment from given line of CSV only")
+	explainCmd.Flags().StringVar(&explainNote, "note", "",
+		"explain payments with note matching given regexp only")
+}
+
+func explainRecord(rec app.Record) error {
+	m, err := cfg.MatchSection(rec)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println(rec.String())
+	if m.Override != nil {
+		fmt.Printf("  %s\n", m.Override)
+	}
+
+	switch criteria := m.Criteria(); {
+	case !m.Found():
+		fmt.Println("  no matching rule")
+	case len(criteria) == 0:
+		fmt.Printf("  section: %q, pinned by override\n", m.Section)
+		fmt.Printf("  key: %q\n", m.Key)
+	default:
+		fmt.Printf("  section: %q, rule: %d, matched by: %s\n", m.Section, m.Rule,
+			strings.Join(criteria, ", "))
+		fmt.Printf("  key: %q\n", m.Key)
+		if len(m.Tags) > 0 {
+			fmt.Printf("  tags: %s\n", strings.Join(m.Tags, ", "))
+		}
+	}
+	fmt.Println()
+
+	return nil
+}
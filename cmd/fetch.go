@@ -0,0 +1,49 @@
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/dsh2dsh/fio/internal/app"
+)
+
+var (
+	fetchFrom string
+
+	fetchCmd = &cobra.Command{
+		Use:   "fetch",
+		Short: "Download new payments from Fio API into archive directory",
+		Long: `It downloads new payments from Fio API and saves them as a JSON statement into
+archive directory, configured in config file. The archive directory can be
+given to the report command as input.
+
+The token of Fio API is read from config file or FIO_TOKEN environment
+variable. Next time it downloads payments after last downloaded one.`,
+		Args: cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			fetcher := app.NewFetcher(&cfg.Fetch)
+			if fetchFrom != "" {
+				d, err := time.Parse("2006-01-02", fetchFrom)
+				cobra.CheckErr(err)
+				fetcher.WithFromDate(d)
+			}
+
+			path, n, err := fetcher.Fetch(cmd.Context())
+			cobra.CheckErr(err)
+			if n == 0 {
+				fmt.Println("No new payments.")
+			} else {
+				fmt.Printf("%d new payments saved to %s\n", n, path)
+			}
+		},
+	}
+)
+
+func init() {
+	rootCmd.AddCommand(fetchCmd)
+
+	fetchCmd.Flags().StringVar(&fetchFrom, "from", "",
+		"download payments since given date (in format YYYY-MM-DD)")
+}
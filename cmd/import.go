@@ -0,0 +1,38 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/dsh2dsh/fio/internal/app"
+)
+
+var importCmd = &cobra.Command{
+	Use:   "import [input.csv...]",
+	Short: "Import payments into local store",
+	Long: `It reads payments from input files or stdin and appends them into local store,
+configured in config file, skipping payments, which are already there. The
+report command reads payments from the store with --store flag.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if fromStore {
+			cobra.CheckErr(errors.New("--store can't be used with import"))
+		}
+
+		store := app.NewStore(cfg)
+		var added, skipped int
+		withInputFiles(args, func(src app.Source) error {
+			n, dups, err := store.Import(src)
+			added += n
+			skipped += dups
+			return err
+		})
+		fmt.Printf("%d new payments imported into %s, %d duplicates skipped.\n",
+			added, store.Path(), skipped)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(importCmd)
+}
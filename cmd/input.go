@@ -0,0 +1,122 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/dsh2dsh/fio/internal/app"
+)
+
+// withInputFiles calls fn for source of records of every input file from args
+// or for stdin, if args are empty. Directories are expanded to input files
+// inside of them and glob patterns, not expanded by shell, to matched files.
+// With --store it calls fn for local store instead.
+func withInputFiles(args []string, fn func(src app.Source) error) {
+	if fromStore {
+		if len(args) > 0 {
+			cobra.CheckErr(errors.New("--store can't be used with input files"))
+		}
+		withStore(fn)
+		return
+	}
+
+	csvFormat, err := cfg.CSVProfile(profile)
+	cobra.CheckErr(err)
+
+	if len(args) == 0 {
+		src, err := app.NewSource(os.Stdin, inputFormat, csvFormat)
+		cobra.CheckErr(err)
+		cobra.CheckErr(fn(src))
+		return
+	}
+
+	paths, err := expandInputs(args)
+	cobra.CheckErr(err)
+	for _, path := range paths {
+		if err := withInputFile(path, csvFormat, fn); err != nil {
+			cobra.CheckErr(fmt.Errorf("%s: %w", path, err))
+		}
+	}
+}
+
+func withInputFile(path string, csvFormat *app.CSVConfig,
+	fn func(src app.Source) error,
+) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("open input: %w", err)
+	}
+	defer file.Close()
+
+	format := inputFormat
+	if format == "" {
+		format = app.FormatByExt(path)
+	}
+
+	src, err := app.NewSource(file, format, csvFormat)
+	if err != nil {
+		return err
+	}
+	return fn(src)
+}
+
+func withStore(fn func(src app.Source) error) {
+	store := app.NewStore(cfg)
+	r, err := store.Open()
+	cobra.CheckErr(err)
+	defer r.Close()
+
+	if err := fn(r); err != nil {
+		cobra.CheckErr(fmt.Errorf("%s: %w", store.Path(), err))
+	}
+}
+
+func expandInputs(args []string) ([]string, error) {
+	paths := make([]string, 0, len(args))
+	for _, arg := range args {
+		stat, err := os.Stat(arg)
+		switch {
+		case err == nil && stat.IsDir():
+			files, err := inputsInDir(arg)
+			if err != nil {
+				return nil, err
+			}
+			paths = append(paths, files...)
+		case err == nil:
+			paths = append(paths, arg)
+		case os.IsNotExist(err) && strings.ContainsAny(arg, "*?["):
+			files, err := filepath.Glob(arg)
+			if err != nil {
+				return nil, fmt.Errorf("glob %q: %w", arg, err)
+			} else if len(files) == 0 {
+				return nil, fmt.Errorf("no files match %q", arg)
+			}
+			paths = append(paths, files...)
+		default:
+			return nil, fmt.Errorf("input %q: %w", arg, err)
+		}
+	}
+	return paths, nil
+}
+
+func inputsInDir(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("read dir %q: %w", dir, err)
+	}
+
+	var paths []string
+	for _, entry := range entries {
+		name := entry.Name()
+		if !entry.IsDir() && !strings.HasPrefix(name, ".") &&
+			app.FormatByExt(name) != "" {
+			paths = append(paths, filepath.Join(dir, name))
+		}
+	}
+	return paths, nil
+}
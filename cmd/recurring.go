@@ -0,0 +1,52 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/dsh2dsh/fio/internal/app"
+)
+
+var recurringCmd = &cobra.Command{
+	Use:   "recurring [input.csv...]",
+	Short: "Detect recurring payments, like subscriptions",
+	Long: `It groups payments by account and VS, or by section and key of matched rule,
+and detects monthly, quarterly and yearly payments. For every recurring payment
+it shows its last amount, count, last and next expected date and changes of
+price.
+
+Expected payments, which didn't come till end of the period, given by --month
+or --to-date, or till today, are marked missing. Payments, which missed more
+than one period, are marked stopped. All payments from input files are used
+for detection, till end of the period.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		_, asOf, err := parsePeriod(oneMonth, fromDate, toDate)
+		cobra.CheckErr(err)
+
+		report := withDirection(app.NewReport(cfg))
+		if asOf.IsZero() {
+			asOf = time.Now()
+		} else {
+			report = report.WithToDate(asOf)
+		}
+
+		recurring := app.NewRecurring(cfg)
+		withInputFiles(args, func(src app.Source) error {
+			return report.Each(src, recurring.Add)
+		})
+
+		payments := recurring.Detect(asOf)
+		if len(payments) == 0 {
+			fmt.Fprintln(os.Stderr, "No recurring payments found.")
+			return
+		}
+		cobra.CheckErr(app.WriteRecurring(os.Stdout, payments))
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(recurringCmd)
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/spf13/cobra"
@@ -17,33 +18,44 @@ const yamlFileName = ".fio.yaml"
 var (
 	cfg     *app.Config
 	cfgFile string
+	// cfgPath is a path of loaded config file
+	cfgPath string
 
 	fromDate string
 	toDate   string
 	oneMonth string
 
+	direction   string
+	strict      bool
+	profile     string
+	inputFormat string
+	fromStore   bool
+
+	output         string
+	failOverBudget bool
+	tag            string
+
 	// rootCmd represents the base command when called without any subcommands
 	rootCmd = &cobra.Command{
-		Use:   "fio [input.csv]",
+		Use:   "fio [input.csv...]",
 		Short: "Generator of a report of money expenses using CSV from Fio banka.",
 		Long: `This program reads a CSV file or stdin, processes it and outputs an aggreagated
-report of your expenses.`,
+report of your expenses and, optionally, income.`,
+		Args: cobra.ArbitraryArgs,
 		Run: func(cmd *cobra.Command, args []string) {
-			inputFile := os.Stdin
-			if len(args) > 0 {
-				file, err := os.Open(args[0])
-				cobra.CheckErr(err)
-				defer file.Close()
-				inputFile = file
-			}
-
-			report := withFromToDates(app.NewReport(cfg))
-			cobra.CheckErr(report.Parse(inputFile))
-			if report.Data().Count() > 0 {
-				cobra.CheckErr(report.Print())
+			report := newReport().WithStrict(strict).WithTag(tag)
+			withInputFiles(args, report.Parse)
+			printDuplicates(report)
+			printUnusedOverrides(report)
+			printUnmatched(report)
+			if !report.Data().Empty() {
+				cobra.CheckErr(report.Print(os.Stdout, output))
 			} else {
 				fmt.Fprintln(os.Stderr, "Nothing found for given dates.")
 			}
+			if printOverBudget(report.Data()) && failOverBudget {
+				cobra.CheckErr(errors.New("over budget"))
+			}
 		},
 	}
 )
@@ -76,6 +88,25 @@ func init() {
 		"include payments for given month (in format YYYY-MM)")
 	rootCmd.MarkFlagsMutuallyExclusive("month", "from-date")
 	rootCmd.MarkFlagsMutuallyExclusive("month", "to-date")
+
+	rootCmd.PersistentFlags().StringVar(&direction, "direction", "out",
+		"include outgoing (out), incoming (in) or all (both) payments")
+	rootCmd.PersistentFlags().BoolVar(&strict, "strict", false,
+		"fail on first payment, which doesn't match any section")
+	rootCmd.PersistentFlags().StringVarP(&profile, "profile", "p", "",
+		"read CSV using given profile from config file")
+	rootCmd.PersistentFlags().StringVar(&inputFormat, "format", "",
+		fmt.Sprintf("format of input files: %s (default by file extension or csv)",
+			strings.Join(app.Formats, ", ")))
+	rootCmd.PersistentFlags().BoolVarP(&fromStore, "store", "s", false,
+		"read payments from local store instead of input files")
+
+	rootCmd.Flags().StringVarP(&output, "output", "o", app.OutputText,
+		"output format: "+strings.Join(app.Outputs, ", "))
+	rootCmd.Flags().StringVarP(&tag, "tag", "t", "",
+		"include payments with given tag only")
+	rootCmd.Flags().BoolVar(&failOverBudget, "fail-over-budget", false,
+		"exit with error, if any section or rule is over budget")
 }
 
 func initConfig() {
@@ -112,7 +143,7 @@ func tryConfig(path string, mustExists bool) bool {
 		}
 		cobra.CheckErr(err)
 	}
-	cfg = c
+	cfg, cfgPath = c, path
 	return true
 }
 
@@ -124,40 +155,102 @@ func homeCfgPath() (string, error) {
 	}
 }
 
+func newReport() *app.Report {
+	return withDirection(withFromToDates(app.NewReport(cfg)))
+}
+
 func withFromToDates(report *app.Report) *app.Report {
-	if report, ok := withOneMonth(report); ok {
-		return report
+	from, to, err := parsePeriod(oneMonth, fromDate, toDate)
+	cobra.CheckErr(err)
+	return withPeriod(report, from, to)
+}
+
+// parsePeriod returns first and last dates of period, given by month or by from
+// and to dates. Zero date means period isn't limited from this side.
+func parsePeriod(month, from, to string) (fromDate, toDate time.Time, err error) {
+	if month != "" {
+		d, err := time.Parse("2006-01", month)
+		if err != nil {
+			return d, d, err
+		}
+		return d, d.AddDate(0, 1, -1), nil
 	}
 
-	if fromDate != "" {
-		if d, err := time.Parse("2006-01-02", fromDate); err != nil {
-			cobra.CheckErr(err)
-		} else {
-			report = report.WithFromDate(d)
+	if from != "" {
+		if fromDate, err = time.Parse("2006-01-02", from); err != nil {
+			return
 		}
 	}
 
-	if toDate != "" {
-		if d, err := time.Parse("2006-01-02", toDate); err != nil {
-			cobra.CheckErr(err)
-		} else {
-			report = report.WithToDate(d)
+	if to != "" {
+		if toDate, err = time.Parse("2006-01-02", to); err != nil {
+			return
 		}
 	}
 
-	return report
+	return
 }
 
-func withOneMonth(report *app.Report) (*app.Report, bool) {
-	if oneMonth == "" {
-		return report, false
+func withPeriod(report *app.Report, from, to time.Time) *app.Report {
+	if !from.IsZero() {
+		report = report.WithFromDate(from)
+	}
+	if !to.IsZero() {
+		report = report.WithToDate(to)
 	}
+	return report
+}
 
-	d, err := time.Parse("2006-01", oneMonth)
+func withDirection(report *app.Report) *app.Report {
+	d, err := app.ParseDirection(direction)
 	if err != nil {
 		cobra.CheckErr(err)
 	}
-	report = report.WithFromDate(d).WithToDate(d.AddDate(0, 1, -1))
+	return report.WithDirection(d)
+}
+
+func printDuplicates(report *app.Report) {
+	if n := report.Duplicates(); n > 0 {
+		fmt.Fprintf(os.Stderr, "%d duplicate payments skipped.\n", n)
+	}
+}
+
+func printUnusedOverrides(report *app.Report) {
+	unused := report.UnusedOverrides()
+	if len(unused) == 0 {
+		return
+	}
+
+	fmt.Fprintf(os.Stderr, "%d overrides don't match any payment:\n", len(unused))
+	for _, o := range unused {
+		fmt.Fprintln(os.Stderr, "  "+o.String())
+	}
+}
 
-	return report, true
+func printUnmatched(report *app.Report) {
+	unmatched := report.Unmatched()
+	if len(unmatched) == 0 {
+		return
+	}
+
+	fmt.Fprintf(os.Stderr, "%d unmatched payments added to %q:\n",
+		len(unmatched), cfg.Uncategorized)
+	for _, rec := range unmatched {
+		fmt.Fprintln(os.Stderr, "  "+rec.String())
+	}
+}
+
+// printOverBudget prints exceeded budgets and returns true, if there are any.
+func printOverBudget(data *app.ReportData) bool {
+	over := data.OverBudget()
+	if len(over) == 0 {
+		return false
+	}
+
+	fmt.Fprintf(os.Stderr, "%d budgets exceeded:\n", len(over))
+	for _, b := range over {
+		fmt.Fprintf(os.Stderr, "  %s: spent %.02f of %.02f (%.0f%%)\n", b.Name(),
+			b.Spent(), b.Budget(), b.PercentUsed())
+	}
+	return true
 }
@@ -0,0 +1,37 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/dsh2dsh/fio/internal/app"
+)
+
+var suggestCmd = &cobra.Command{
+	Use:   "suggest [input.csv...]",
+	Short: "Suggest rules for payments, which don't match any section",
+	Long: `It clusters payments, which don't match any rule, by account and VS, or by
+prefix of note, like "Nákup: MERCHANT,", and writes suggested rules as YAML,
+ready for pasting into rules of a section. Rules are ranked by total money of
+their payments.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		report := newReport()
+		suggester := app.NewSuggester(cfg)
+		withInputFiles(args, func(src app.Source) error {
+			return report.Each(src, suggester.Add)
+		})
+
+		suggestions := suggester.Suggestions()
+		if len(suggestions) == 0 {
+			fmt.Fprintln(os.Stderr, "All payments match rules.")
+			return
+		}
+		cobra.CheckErr(app.WriteSuggestions(os.Stdout, suggestions))
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(suggestCmd)
+}
@@ -0,0 +1,259 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"slices"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+const (
+	defaultAnomalyThreshold  = 3.5
+	defaultAnomalyMinSamples = 5
+)
+
+// AnomalyConfig configures detection of unusually large payments of a section.
+// Subsections inherit it from parent section.
+type AnomalyConfig struct {
+	// Threshold is a minimal modified z-score of anomaly.
+	Threshold float64
+	// MinSamples is a minimal number of payments of item or section for
+	// detection.
+	MinSamples int `yaml:"minSamples"`
+}
+
+func defaultAnomalyConfig() *AnomalyConfig {
+	return &AnomalyConfig{
+		Threshold:  defaultAnomalyThreshold,
+		MinSamples: defaultAnomalyMinSamples,
+	}
+}
+
+func (self *AnomalyConfig) compile() error {
+	switch {
+	case self.Threshold < 0:
+		return errors.New("anomaly: negative threshold")
+	case self.MinSamples < 0:
+		return errors.New("anomaly: negative minSamples")
+	}
+	return nil
+}
+
+// anomalyConfig returns anomaly config of section. Unset properties are
+// inherited from the nearest parent, which has them, and then default.
+func (self *Config) anomalyConfig(sectName string) *AnomalyConfig {
+	cfg := &AnomalyConfig{}
+	for sect := self.sectionIndex[sectName]; sect != nil; sect = sect.parent {
+		if a := sect.Anomaly; a != nil {
+			if cfg.Threshold == 0 {
+				cfg.Threshold = a.Threshold
+			}
+			if cfg.MinSamples == 0 {
+				cfg.MinSamples = a.MinSamples
+			}
+		}
+	}
+
+	def := defaultAnomalyConfig()
+	if cfg.Threshold == 0 {
+		cfg.Threshold = def.Threshold
+	}
+	if cfg.MinSamples == 0 {
+		cfg.MinSamples = def.MinSamples
+	}
+	return cfg
+}
+
+// --------------------------------------------------
+
+func NewAnomalies(cfg *Config) *Anomalies {
+	return &Anomalies{
+		cfg:     cfg,
+		samples: make(map[string][]float64),
+	}
+}
+
+// Anomalies finds payments, which are much larger than usual payments of their
+// item or section. It uses modified z-score, based on median and median
+// absolute deviation.
+type Anomalies struct {
+	cfg     *Config
+	records []anomalyRecord
+	// amounts of money keyed by section or by section and item
+	samples map[string][]float64
+}
+
+type anomalyRecord struct {
+	rec     Record
+	section string
+	key     string
+	money   Money
+}
+
+func sectionSamplesKey(sectName string) string {
+	return sectName
+}
+
+func itemSamplesKey(sectName, key string) string {
+	return sectName + "\x00" + key
+}
+
+// Add adds a payment into history of its section and item.
+func (self *Anomalies) Add(rec Record) error {
+	m, err := self.cfg.MatchSection(rec)
+	if err != nil {
+		return err
+	} else if !m.Found() {
+		m.Section, m.Key = self.cfg.Uncategorized, rec.Note()
+	}
+
+	money, err := self.cfg.Convert(rec.Money(), rec.Date())
+	if err != nil {
+		return fmt.Errorf("line %d: %w", rec.Line(), err)
+	}
+
+	self.records = append(self.records, anomalyRecord{rec, m.Section, m.Key, money})
+	for _, k := range []string{
+		sectionSamplesKey(m.Section), itemSamplesKey(m.Section, m.Key),
+	} {
+		self.samples[k] = append(self.samples[k], money.Float())
+	}
+	return nil
+}
+
+// Detect returns anomalies between given dates, largest score first. Zero date
+// means period isn't limited from this side.
+func (self *Anomalies) Detect(from, to time.Time) []*Anomaly {
+	cache := make(map[string]*anomalyStats)
+	var anomalies []*Anomaly
+	for _, r := range self.records {
+		if !r.rec.Between(from, to) {
+			continue
+		}
+
+		cfg := self.cfg.anomalyConfig(r.section)
+		k, byItem := itemSamplesKey(r.section, r.key), true
+		if len(self.samples[k]) < cfg.MinSamples {
+			k, byItem = sectionSamplesKey(r.section), false
+			if len(self.samples[k]) < cfg.MinSamples {
+				continue
+			}
+		}
+
+		stats := cache[k]
+		if stats == nil {
+			stats = newAnomalyStats(self.samples[k])
+			cache[k] = stats
+		}
+
+		if score := stats.score(r.money.Float()); score >= cfg.Threshold {
+			median := int64(math.Round(stats.median * moneyScale))
+			anomalies = append(anomalies, &Anomaly{
+				Record:  r.rec,
+				Section: r.section,
+				Key:     r.key,
+				Money:   r.money,
+				Median:  NewMoney(median, r.money.Currency()),
+				Score:   score,
+				ByItem:  byItem,
+			})
+		}
+	}
+
+	sort.SliceStable(anomalies, func(i, j int) bool {
+		return anomalies[i].Score > anomalies[j].Score
+	})
+	return anomalies
+}
+
+// --------------------------------------------------
+
+type anomalyStats struct {
+	median float64
+	// scale is a robust deviation of samples, converted to comparable with
+	// standard deviation
+	scale float64
+}
+
+func newAnomalyStats(samples []float64) *anomalyStats {
+	median := medianOf(samples)
+	deviations := make([]float64, len(samples))
+	var sumDeviations float64
+	for i, v := range samples {
+		deviations[i] = math.Abs(v - median)
+		sumDeviations += deviations[i]
+	}
+
+	stats := &anomalyStats{median: median}
+	if mad := medianOf(deviations); mad > 0 {
+		stats.scale = mad / 0.6745
+	} else {
+		// more than half of samples are equal, like fixed payments, so use mean
+		// absolute deviation
+		stats.scale = 1.253314 * sumDeviations / float64(len(samples))
+	}
+	return stats
+}
+
+// score returns modified z-score of larger than median values and zero for
+// others.
+func (self *anomalyStats) score(v float64) float64 {
+	if v <= self.median || self.scale == 0 {
+		return 0
+	}
+	return (v - self.median) / self.scale
+}
+
+func medianOf(samples []float64) float64 {
+	sorted := slices.Clone(samples)
+	slices.Sort(sorted)
+	n := len(sorted)
+	if n%2 == 1 {
+		return sorted[n/2]
+	}
+	return (sorted[n/2-1] + sorted[n/2]) / 2
+}
+
+// --------------------------------------------------
+
+// Anomaly is a payment, which is much larger than usual payments of its item
+// or section.
+type Anomaly struct {
+	Record  Record
+	Section string
+	Key     string
+	// Money is money of the payment in reporting currency and Median is median
+	// of payments, it's compared with.
+	Money  Money
+	Median Money
+	Score  float64
+	// ByItem is true, if payment is compared with payments of its item, and
+	// false for payments of its section.
+	ByItem bool
+}
+
+// WriteAnomalies writes a table of anomalies.
+func WriteAnomalies(w io.Writer, anomalies []*Anomaly) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "Date\tLine\tSection\tItem\tMoney\tMedian\tScore\tNote")
+	for _, a := range anomalies {
+		median := "section " + a.Median.String()
+		if a.ByItem {
+			median = "item " + a.Median.String()
+		}
+		fmt.Fprintf(tw, "%s\t%d\t%s\t%s\t%.02f\t%s\t%.1f\t%s\n",
+			a.Record.Date().Format("2006-01-02"), a.Record.Line(), a.Section,
+			strings.ReplaceAll(a.Key, "\t", " "), a.Money, median, a.Score,
+			strings.ReplaceAll(a.Record.Note(), "\t", " "))
+	}
+
+	if err := tw.Flush(); err != nil {
+		return fmt.Errorf("write anomalies: %w", err)
+	}
+	return nil
+}
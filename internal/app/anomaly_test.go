@@ -0,0 +1,199 @@
+package app
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestAnomalyStats(t *testing.T) {
+	tests := []struct {
+		name    string
+		samples []float64
+		v       float64
+		median  float64
+		score   float64
+	}{
+		{
+			name:    "median absolute deviation",
+			samples: []float64{100, 110, 90, 105, 95, 300},
+			v:       300,
+			median:  102.5,
+			score:   197.5 / (7.5 / 0.6745),
+		},
+		{
+			name:    "on threshold",
+			samples: []float64{100, 110, 90, 105, 95, 300},
+			v:       102.5 + 3.5*7.5/0.6745,
+			median:  102.5,
+			score:   3.5,
+		},
+		{
+			name:    "smaller than median",
+			samples: []float64{100, 110, 90, 105, 95, 300},
+			v:       50,
+			median:  102.5,
+		},
+		{
+			name:    "mean absolute deviation, if MAD is zero",
+			samples: []float64{100, 100, 100, 100, 200},
+			v:       200,
+			median:  100,
+			score:   100 / (1.253314 * 20),
+		},
+		{
+			name:    "all equal",
+			samples: []float64{100, 100, 100},
+			v:       200,
+			median:  100,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stats := newAnomalyStats(tt.samples)
+			if stats.median != tt.median {
+				t.Errorf("median = %v, want %v", stats.median, tt.median)
+			}
+			if got := stats.score(tt.v); math.Abs(got-tt.score) > 1e-9 {
+				t.Errorf("score(%v) = %v, want %v", tt.v, got, tt.score)
+			}
+		})
+	}
+}
+
+const testAnomalyConfig = `
+sections:
+  - name: Food
+    rules:
+      - re: '^Nákup: (ALBERT|LIDL|BAKERY),'
+  - name: Home
+    anomaly:
+      threshold: 5
+      minSamples: 3
+    sections:
+      - name: Energy
+        rules:
+          - re: '^Energy'
+            key: Power
+      - name: Water
+        anomaly:
+          threshold: 2
+`
+
+func TestConfig_anomalyConfig(t *testing.T) {
+	cfg := mustLoadConfig(t, testAnomalyConfig)
+	tests := []struct {
+		section    string
+		threshold  float64
+		minSamples int
+	}{
+		{section: "Food", threshold: 3.5, minSamples: 5},
+		{section: "Home", threshold: 5, minSamples: 3},
+		{section: "Energy", threshold: 5, minSamples: 3},
+		{section: "Water", threshold: 2, minSamples: 3},
+		{section: "Uncategorized", threshold: 3.5, minSamples: 5},
+	}
+
+	for _, tt := range tests {
+		got := cfg.anomalyConfig(tt.section)
+		if got.Threshold != tt.threshold || got.MinSamples != tt.minSamples {
+			t.Errorf("anomalyConfig(%q) = %+v, want threshold %v, minSamples %d",
+				tt.section, got, tt.threshold, tt.minSamples)
+		}
+	}
+}
+
+func TestAnomalies_Detect(t *testing.T) {
+	cfg := mustLoadConfig(t, testAnomalyConfig)
+	payments := []struct {
+		date   string
+		amount int64
+		note   string
+	}{
+		// enough payments of item
+		{"2026-06-10", 10000, "Nákup: ALBERT, Praha, CZ"},
+		{"2026-07-10", 11000, "Nákup: ALBERT, Praha, CZ"},
+		{"2026-07-20", 9000, "Nákup: ALBERT, Praha, CZ"},
+		{"2026-08-10", 10500, "Nákup: ALBERT, Praha, CZ"},
+		{"2026-08-20", 9500, "Nákup: ALBERT, Praha, CZ"},
+		{"2026-09-10", 30000, "Nákup: ALBERT, Praha, CZ"},
+		// not enough payments of item, compared with section
+		{"2026-07-15", 10000, "Nákup: LIDL, Praha, CZ"},
+		{"2026-08-15", 40000, "Nákup: LIDL, Praha, CZ"},
+		// fixed payments, score 3.99
+		{"2026-06-01", 10000, "Nákup: BAKERY, Praha, CZ"},
+		{"2026-07-01", 10000, "Nákup: BAKERY, Praha, CZ"},
+		{"2026-08-01", 10000, "Nákup: BAKERY, Praha, CZ"},
+		{"2026-08-02", 10000, "Nákup: BAKERY, Praha, CZ"},
+		{"2026-08-03", 20000, "Nákup: BAKERY, Praha, CZ"},
+		// the same score, but under threshold 5 of parent section
+		{"2026-06-05", 100000, "Energy"},
+		{"2026-07-05", 100000, "Energy"},
+		{"2026-08-05", 100000, "Energy"},
+		{"2026-08-06", 100000, "Energy"},
+		{"2026-09-05", 200000, "Energy"},
+	}
+
+	anomalies := NewAnomalies(cfg)
+	for i, p := range payments {
+		rec := testRecord(t, i+1, p.date, -p.amount, p.note)
+		if err := anomalies.Add(rec); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	type want struct {
+		line   int
+		key    string
+		median string
+		byItem bool
+	}
+	tests := []struct {
+		name string
+		from string
+		to   string
+		want []want
+	}{
+		{
+			name: "all",
+			want: []want{
+				{line: 8, key: "LIDL", median: "100.00"},
+				{line: 6, key: "ALBERT", median: "102.50", byItem: true},
+				{line: 13, key: "BAKERY", median: "100.00", byItem: true},
+			},
+		},
+		{
+			name: "period",
+			from: "2026-09-01",
+			to:   "2026-09-30",
+			want: []want{{line: 6, key: "ALBERT", median: "102.50", byItem: true}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var from, to time.Time
+			if tt.from != "" {
+				from, to = testDate(t, tt.from), testDate(t, tt.to)
+			}
+
+			got := anomalies.Detect(from, to)
+			if len(got) != len(tt.want) {
+				for _, a := range got {
+					t.Logf("%s: %s %.1f", a.Record.String(), a.Key, a.Score)
+				}
+				t.Fatalf("Detect() returned %d anomalies, want %d", len(got),
+					len(tt.want))
+			}
+			for i, a := range got {
+				w := tt.want[i]
+				if a.Record.Line() != w.line || a.Key != w.key ||
+					a.Median.String() != w.median || a.ByItem != w.byItem {
+					t.Errorf("anomaly %d: line %d, key %q, median %s, by item %v, want %+v",
+						i, a.Record.Line(), a.Key, a.Median, a.ByItem, w)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,124 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+)
+
+// BudgetConfig is a budget of section or rule. It's defined per month or per
+// year and prorated for months of a report.
+type BudgetConfig struct {
+	Monthly string
+	Yearly  string
+
+	monthly Money
+	yearly  Money
+}
+
+func (self *BudgetConfig) compile(currency string) (err error) {
+	switch {
+	case self.Monthly != "" && self.Yearly != "":
+		return errors.New("both monthly and yearly budget defined")
+	case self.Monthly != "":
+		self.monthly, err = parseConfigMoney(self.Monthly, currency)
+	case self.Yearly != "":
+		self.yearly, err = parseConfigMoney(self.Yearly, currency)
+	default:
+		return errors.New("empty budget")
+	}
+	return
+}
+
+// prorate returns budget for given number of months.
+func (self *BudgetConfig) prorate(months int) Money {
+	if self.Yearly != "" {
+		return Money{
+			amount:   divRound(self.yearly.amount*int64(months), 12),
+			currency: self.yearly.currency,
+		}
+	}
+	return Money{
+		amount:   self.monthly.amount * int64(months),
+		currency: self.monthly.currency,
+	}
+}
+
+func (self *Config) compileBudgets() error {
+	return self.WalkSections(func(sect *SectionConfig) error {
+		if sect.Income {
+			return checkNoIncomeBudgets(sect)
+		}
+
+		if sect.Budget != nil {
+			if err := sect.Budget.compile(self.Currency); err != nil {
+				return fmt.Errorf("config: section %q: %w", sect.Name, err)
+			}
+		}
+		for i, rule := range sect.Rules {
+			if rule.Budget == nil {
+				continue
+			} else if err := rule.Budget.compile(self.Currency); err != nil {
+				return fmt.Errorf("config: section %q, rule %d: %w", sect.Name, i, err)
+			}
+		}
+		return nil
+	})
+}
+
+// checkNoIncomeBudgets returns error, if income section or its rules have a
+// budget. Budget is a limit of spending, it has no meaning for income.
+func checkNoIncomeBudgets(sect *SectionConfig) error {
+	if sect.Budget != nil {
+		return fmt.Errorf("config: section %q: budget of income section", sect.Name)
+	}
+	for i, rule := range sect.Rules {
+		if rule.Budget != nil {
+			return fmt.Errorf("config: section %q, rule %d: budget of income section",
+				sect.Name, i)
+		}
+	}
+	return nil
+}
+
+// --------------------------------------------------
+
+func newBudget(name string, budget, spent Money) *Budget {
+	return &Budget{name: name, budget: budget, spent: spent}
+}
+
+// Budget is a budget of section or rule for period of a report and money spent
+// in this period.
+type Budget struct {
+	name   string
+	budget Money
+	spent  Money
+}
+
+func (self *Budget) Name() string {
+	return self.name
+}
+
+func (self *Budget) Budget() Money {
+	return self.budget
+}
+
+func (self *Budget) Spent() Money {
+	return self.spent
+}
+
+// Remaining returns money left from budget, negative when it's over budget.
+func (self *Budget) Remaining() Money {
+	return self.budget.Sub(self.spent)
+}
+
+// PercentUsed returns spent money in percents of budget.
+func (self *Budget) PercentUsed() float64 {
+	if self.budget.IsZero() {
+		return 0
+	}
+	return self.spent.Float() / self.budget.Float() * 100
+}
+
+func (self *Budget) Over() bool {
+	return self.spent.Cmp(self.budget) > 0
+}
@@ -0,0 +1,279 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"slices"
+	"text/template"
+	"text/template/parse"
+
+	"gopkg.in/yaml.v3"
+)
+
+// NewConfigChecker returns checker of config, loaded from given path, for
+// problems, which don't prevent loading of it, but make it work not as
+// expected.
+func NewConfigChecker(cfg *Config, path string) *ConfigChecker {
+	return &ConfigChecker{
+		cfg:     cfg,
+		path:    path,
+		matched: make(map[*SectionRule]struct{}),
+	}
+}
+
+type ConfigChecker struct {
+	cfg  *Config
+	path string
+
+	matched map[*SectionRule]struct{}
+	seenIn  bool
+	seenOut bool
+}
+
+// ConfigProblem is a problem of config, found by ConfigChecker.
+type ConfigProblem struct {
+	// Where is a place of the problem, like section and rule.
+	Where   string
+	Message string
+}
+
+func (self ConfigProblem) String() string {
+	return self.Where + ": " + self.Message
+}
+
+// checkedRule is a rule with its section, in order of matching.
+type checkedRule struct {
+	sect *SectionConfig
+	idx  int
+	rule *SectionRule
+}
+
+func (self *checkedRule) String() string {
+	return fmt.Sprintf("section %q, rule %d", self.sect.Name, self.idx)
+}
+
+// Add marks rule, which matches given record, as used. After adding records
+// Check reports rules, which never matched any of them.
+func (self *ConfigChecker) Add(rec Record) error {
+	m, err := self.cfg.MatchSection(rec)
+	if err != nil {
+		return err
+	} else if m.rule != nil {
+		self.matched[m.rule] = struct{}{}
+	}
+
+	if rec.Out() {
+		self.seenOut = true
+	} else {
+		self.seenIn = true
+	}
+	return nil
+}
+
+// Check returns found problems of config.
+func (self *ConfigChecker) Check() ([]ConfigProblem, error) {
+	problems, err := self.checkKeys()
+	if err != nil {
+		return nil, err
+	}
+
+	rules := rulesInMatchOrder(self.cfg.Sections)
+	shadowed := make(map[*SectionRule]struct{})
+	problems = slices.Concat(problems,
+		checkShadowed(rules, shadowed), checkRules(rules),
+		self.checkTemplate(), self.checkUnmatched(rules, shadowed))
+	return problems, nil
+}
+
+// checkKeys returns problems for keys of config and overrides files, which
+// aren't known and silently ignored by LoadConfig.
+func (self *ConfigChecker) checkKeys() ([]ConfigProblem, error) {
+	problems, err := checkYAMLKeys(self.path, &Config{})
+	if err != nil || self.cfg.OverridesFile == "" {
+		return problems, err
+	}
+
+	path, err := expandHomeDir(self.cfg.OverridesFile)
+	if err != nil {
+		return nil, fmt.Errorf("expand home dir in %q: %w", self.cfg.OverridesFile,
+			err)
+	}
+	overrides, err := checkYAMLKeys(path, &overridesFile{})
+	if err != nil {
+		return nil, err
+	}
+	return append(problems, overrides...), nil
+}
+
+func checkYAMLKeys(path string, v any) ([]ConfigProblem, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("open %q: %w", path, err)
+	}
+	defer file.Close()
+
+	dec := yaml.NewDecoder(file)
+	dec.KnownFields(true)
+	if err := dec.Decode(v); err != nil {
+		var typeErr *yaml.TypeError
+		if !errors.As(err, &typeErr) {
+			return nil, fmt.Errorf("yaml decode %q: %w", path, err)
+		}
+		problems := make([]ConfigProblem, len(typeErr.Errors))
+		for i, s := range typeErr.Errors {
+			problems[i] = ConfigProblem{Where: path, Message: s}
+		}
+		return problems, nil
+	}
+	return nil, nil
+}
+
+// rulesInMatchOrder returns rules in the same order, as MatchSection checks
+// them: rules of subsections before rules of their parent.
+func rulesInMatchOrder(sections []*SectionConfig) []checkedRule {
+	var rules []checkedRule
+	for _, sect := range sections {
+		rules = append(rules, rulesInMatchOrder(sect.Sections)...)
+		for i, rule := range sect.Rules {
+			rules = append(rules, checkedRule{sect: sect, idx: i, rule: rule})
+		}
+	}
+	return rules
+}
+
+// checkShadowed returns problems for rules, which never match, because every
+// record they match is matched by some rule before them. Such rules are added
+// into shadowed.
+func checkShadowed(rules []checkedRule, shadowed map[*SectionRule]struct{},
+) []ConfigProblem {
+	var problems []ConfigProblem
+	for i := range rules {
+		r := &rules[i]
+		for j := range rules[:i] {
+			prev := &rules[j]
+			if prev.sect.Income == r.sect.Income && prev.rule.covers(r.rule) {
+				problems = append(problems, ConfigProblem{
+					Where:   r.String(),
+					Message: "unreachable, shadowed by " + prev.String(),
+				})
+				shadowed[r.rule] = struct{}{}
+				break
+			}
+		}
+	}
+	return problems
+}
+
+// checkRules returns problems of single rules: ignored criteria and unknown
+// fields in templates.
+func checkRules(rules []checkedRule) []ConfigProblem {
+	var problems []ConfigProblem
+	for i := range rules {
+		r := &rules[i]
+		if r.rule.Vs != "" && r.rule.Account == "" {
+			problems = append(problems, ConfigProblem{
+				Where:   r.String(),
+				Message: fmt.Sprintf("vs %q is ignored without account", r.rule.Vs),
+			})
+		}
+
+		templates := slices.Concat([]*template.Template{
+			r.rule.keyTemplate, r.rule.ifTemplate,
+		}, r.rule.tagTemplates)
+		var unknown []string
+		for _, t := range templates {
+			if t != nil {
+				unknown = unknownRecordFields(t.Root, unknown)
+			}
+		}
+		for _, name := range unknown {
+			problems = append(problems, ConfigProblem{
+				Where:   r.String(),
+				Message: fmt.Sprintf("template uses unknown field .%s of payment", name),
+			})
+		}
+	}
+	return problems
+}
+
+// unknownRecordFields appends names of fields, used by template node on dot,
+// which Record doesn't have. Dot inside of range and with is something else,
+// so their bodies are skipped, but not their else branches.
+func unknownRecordFields(node parse.Node, unknown []string) []string {
+	add := func(name string) {
+		if _, ok := reflect.TypeFor[templateRecord]().MethodByName(name); !ok &&
+			!slices.Contains(unknown, name) {
+			unknown = append(unknown, name)
+		}
+	}
+
+	switch node := node.(type) {
+	case *parse.ListNode:
+		if node != nil {
+			for _, n := range node.Nodes {
+				unknown = unknownRecordFields(n, unknown)
+			}
+		}
+	case *parse.ActionNode:
+		unknown = unknownRecordFields(node.Pipe, unknown)
+	case *parse.IfNode:
+		unknown = unknownRecordFields(node.Pipe, unknown)
+		unknown = unknownRecordFields(node.List, unknown)
+		unknown = unknownRecordFields(node.ElseList, unknown)
+	case *parse.RangeNode:
+		unknown = unknownRecordFields(node.Pipe, unknown)
+		unknown = unknownRecordFields(node.ElseList, unknown)
+	case *parse.WithNode:
+		unknown = unknownRecordFields(node.Pipe, unknown)
+		unknown = unknownRecordFields(node.ElseList, unknown)
+	case *parse.PipeNode:
+		if node != nil {
+			for _, cmd := range node.Cmds {
+				for _, arg := range cmd.Args {
+					unknown = unknownRecordFields(arg, unknown)
+				}
+			}
+		}
+	case *parse.ChainNode:
+		unknown = unknownRecordFields(node.Node, unknown)
+	case *parse.FieldNode:
+		add(node.Ident[0])
+	case *parse.VariableNode:
+		if node.Ident[0] == "$" && len(node.Ident) > 1 {
+			add(node.Ident[1])
+		}
+	}
+	return unknown
+}
+
+// checkTemplate returns problem, if template of text report can't be parsed.
+func (self *ConfigChecker) checkTemplate() []ConfigProblem {
+	if _, _, err := self.cfg.parseTemplate(); err != nil {
+		return []ConfigProblem{{Where: "template", Message: err.Error()}}
+	}
+	return nil
+}
+
+// checkUnmatched returns problems for rules, which didn't match any of added
+// records. Rules of direction without records and shadowed rules are skipped.
+func (self *ConfigChecker) checkUnmatched(rules []checkedRule,
+	shadowed map[*SectionRule]struct{},
+) []ConfigProblem {
+	var problems []ConfigProblem
+	for i := range rules {
+		r := &rules[i]
+		if r.sect.Income && !self.seenIn || !r.sect.Income && !self.seenOut {
+			continue
+		} else if _, ok := shadowed[r.rule]; ok {
+			continue
+		} else if _, ok := self.matched[r.rule]; !ok {
+			problems = append(problems, ConfigProblem{
+				Where:   r.String(),
+				Message: "never matched any payment",
+			})
+		}
+	}
+	return problems
+}
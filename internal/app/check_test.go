@@ -0,0 +1,312 @@
+package app
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"text/template"
+)
+
+func problemStrings(problems []ConfigProblem) []string {
+	s := make([]string, len(problems))
+	for i, p := range problems {
+		s[i] = p.String()
+	}
+	return s
+}
+
+func TestCheckShadowed(t *testing.T) {
+	tests := []struct {
+		name  string
+		rules string
+		want  []string
+	}{
+		{
+			name: "same account",
+			rules: `
+      - account: 123456/0100
+      - account: 123456/0100
+        key: Other`,
+			want: []string{
+				`section "Test", rule 1: unreachable, shadowed by section "Test", rule 0`,
+			},
+		},
+		{
+			name: "account before account and vs",
+			rules: `
+      - account: 123456/0100
+      - account: 123456/0100
+        vs: "42"`,
+			want: []string{
+				`section "Test", rule 1: unreachable, shadowed by section "Test", rule 0`,
+			},
+		},
+		{
+			name: "account and vs before account",
+			rules: `
+      - account: 123456/0100
+        vs: "42"
+      - account: 123456/0100`,
+		},
+		{
+			name: "different accounts",
+			rules: `
+      - account: 123456/0100
+      - account: 654321/0100`,
+		},
+		{
+			name: "same re",
+			rules: `
+      - re: '^Nákup: (ALBERT|LIDL),'
+      - re: '^Nákup: (ALBERT|LIDL),'
+        key: Shop`,
+			want: []string{
+				`section "Test", rule 1: unreachable, shadowed by section "Test", rule 0`,
+			},
+		},
+		{
+			name: "key without re",
+			rules: `
+      - key: Everything
+      - re: '^Nákup:'`,
+			want: []string{
+				`section "Test", rule 1: unreachable, shadowed by section "Test", rule 0`,
+			},
+		},
+		{
+			name: "different re",
+			rules: `
+      - re: '^Nákup: ALBERT,'
+      - re: '^Nákup: LIDL,'`,
+		},
+		{
+			name: "if on earlier rule",
+			rules: `
+      - account: 123456/0100
+        if: '{{lt .Money 150.0}}'
+        key: Cheap
+      - account: 123456/0100`,
+		},
+		{
+			name: "capture group can be empty",
+			rules: `
+      - re: '^Nákup: (\w*),'
+      - re: '^Nákup: (\w*),'
+        key: Unknown shop`,
+		},
+		{
+			name: "optional capture group",
+			rules: `
+      - re: '^Nákup:( ALBERT)?'
+      - re: '^Nákup:( ALBERT)?'
+        key: Other shop`,
+		},
+		{
+			name: "capture group in alternation",
+			rules: `
+      - re: '^(?:Nákup: (ALBERT)|Platba)'
+      - re: '^(?:Nákup: (ALBERT)|Platba)'
+        key: Payment`,
+		},
+		{
+			name: "key template on earlier rule",
+			rules: `
+      - account: 123456/0100
+        key: '{{.Vs}}'
+      - account: 123456/0100
+        key: Other`,
+		},
+		{
+			name: "note can be empty",
+			rules: `
+      - re: '.*'
+      - re: '.*'
+        key: Empty note`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := mustLoadConfig(t, `
+sections:
+  - name: Test
+    rules:`+tt.rules+"\n")
+			rules := rulesInMatchOrder(cfg.Sections)
+			shadowed := make(map[*SectionRule]struct{})
+			got := problemStrings(checkShadowed(rules, shadowed))
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("checkShadowed() = %q, want %q", got, tt.want)
+			} else if len(shadowed) != len(tt.want) {
+				t.Errorf("%d rules shadowed, want %d", len(shadowed), len(tt.want))
+			}
+		})
+	}
+}
+
+func TestCheckShadowed_sections(t *testing.T) {
+	cfg := mustLoadConfig(t, `
+sections:
+  - name: Home
+    rules:
+      - account: 123456/0100
+    sections:
+      - name: Energy
+        rules:
+          - account: 123456/0100
+            key: Electricity
+  - name: Refunds
+    income: true
+    rules:
+      - account: 123456/0100
+`)
+	rules := rulesInMatchOrder(cfg.Sections)
+	got := problemStrings(checkShadowed(rules, map[*SectionRule]struct{}{}))
+	want := []string{
+		`section "Home", rule 0: unreachable, shadowed by section "Energy", rule 0`,
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("checkShadowed() = %q, want %q", got, want)
+	}
+}
+
+func TestUnknownRecordFields(t *testing.T) {
+	tests := []struct {
+		tmpl string
+		want []string
+	}{
+		{tmpl: `{{.Note}} {{.Money.Float}} {{.Date.Format "2006"}}`},
+		{tmpl: `{{.Notes}} {{.Notes}}`, want: []string{"Notes"}},
+		{tmpl: `{{printf "%s-%s" .Vs .Vss}}`, want: []string{"Vss"}},
+		{tmpl: `{{(.Amnt).Float}}`, want: []string{"Amnt"}},
+		{tmpl: `{{$.Acount}}`, want: []string{"Acount"}},
+		{
+			tmpl: `{{if .Iff}}{{.Then}}{{else}}{{.Else}}{{end}}`,
+			want: []string{"Iff", "Then", "Else"},
+		},
+		{
+			tmpl: `{{range .Items}}{{.Name}}{{else}}{{.Empty}}{{end}}`,
+			want: []string{"Items", "Empty"},
+		},
+		{
+			tmpl: `{{with .Vs}}{{.Len}}{{else}}{{.NoVs}}{{end}}`,
+			want: []string{"NoVs"},
+		},
+		{
+			tmpl: `{{with .Vs}}x{{else with .Ks}}{{.Len}}{{else}}{{.NoKs}}{{end}}`,
+			want: []string{"NoKs"},
+		},
+	}
+
+	for _, tt := range tests {
+		tmpl, err := template.New("").Parse(tt.tmpl)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := unknownRecordFields(tmpl.Root, nil)
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("unknownRecordFields(%q) = %q, want %q", tt.tmpl, got, tt.want)
+		}
+	}
+}
+
+func TestCheckYAMLKeys(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     string
+		want    []string
+		wantErr bool
+	}{
+		{
+			name: "known keys",
+			cfg: `
+currency: CZK
+sections:
+  - name: Food
+    skipPerMonth: true
+`,
+		},
+		{
+			name: "unknown keys",
+			cfg: `
+curency: CZK
+sections:
+  - name: Food
+    skipPerMonht: true
+`,
+			want: []string{
+				"line 2: field curency not found in type app.Config",
+				"line 5: field skipPerMonht not found in type app.SectionConfig",
+			},
+		},
+		{
+			name:    "invalid yaml",
+			cfg:     "sections: [",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "fio.yaml")
+			if err := os.WriteFile(path, []byte(tt.cfg), 0o600); err != nil {
+				t.Fatal(err)
+			}
+
+			problems, err := checkYAMLKeys(path, &Config{})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("checkYAMLKeys() error = %v, want error %v", err, tt.wantErr)
+			}
+
+			var got []string
+			for _, p := range problems {
+				got = append(got, strings.TrimPrefix(p.String(), path+": "))
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("checkYAMLKeys() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConfigChecker_checkUnmatched(t *testing.T) {
+	cfg := mustLoadConfig(t, `
+sections:
+  - name: Food
+    rules:
+      - re: '^Nákup: ALBERT,'
+      - re: '^Nákup: LIDL,'
+      - re: '^Nákup: ALBERT,'
+  - name: Salary
+    income: true
+    rules:
+      - account: 123456/0100
+`)
+	checker := NewConfigChecker(cfg, "")
+	rec := testRecord(t, 1, "2026-03-02", -25010, "Nákup: ALBERT, Praha, CZ")
+	if err := checker.Add(rec); err != nil {
+		t.Fatal(err)
+	}
+
+	rules := rulesInMatchOrder(cfg.Sections)
+	shadowed := make(map[*SectionRule]struct{})
+	checkShadowed(rules, shadowed)
+
+	// shadowed rule and rules of income without incoming payments are skipped
+	got := problemStrings(checker.checkUnmatched(rules, shadowed))
+	want := []string{`section "Food", rule 1: never matched any payment`}
+	if !slices.Equal(got, want) {
+		t.Errorf("checkUnmatched() = %q, want %q", got, want)
+	}
+
+	rec = testRecord(t, 2, "2026-03-05", 5000000, "Salary")
+	if err := checker.Add(rec); err != nil {
+		t.Fatal(err)
+	}
+	got = problemStrings(checker.checkUnmatched(rules, shadowed))
+	want = append(want, `section "Salary", rule 0: never matched any payment`)
+	if !slices.Equal(got, want) {
+		t.Errorf("checkUnmatched() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,213 @@
+package app
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"sort"
+	"strings"
+	"text/tabwriter"
+)
+
+// NewComparison compares sections and items of current report with base one.
+func NewComparison(base, cur *ReportData) *Comparison {
+	return &Comparison{
+		base: base,
+		cur:  cur,
+
+		sections: compareSections(base.sections, cur.sections,
+			cur.SortedSections()),
+		incomeSections: compareSections(base.incomeSections, cur.incomeSections,
+			cur.SortedIncomeSections()),
+	}
+}
+
+type Comparison struct {
+	base *ReportData
+	cur  *ReportData
+
+	sections       []*SectionDelta
+	incomeSections []*SectionDelta
+}
+
+func (self *Comparison) Sections() []*SectionDelta {
+	return self.sections
+}
+
+func (self *Comparison) IncomeSections() []*SectionDelta {
+	return self.incomeSections
+}
+
+// compareSections returns deltas of sections and their subsections in order of
+// current report, followed by disappeared sections.
+func compareSections(base, cur map[string]*Section, sorted []*Section,
+) []*SectionDelta {
+	deltas := make([]*SectionDelta, 0, len(cur))
+	eachSection(sorted, func(sect *Section) {
+		deltas = append(deltas, newSectionDelta(base[sect.Name()], sect))
+	})
+
+	var gone []*SectionDelta
+	for name, sect := range base {
+		if _, ok := cur[name]; !ok {
+			gone = append(gone, newSectionDelta(sect, nil))
+		}
+	}
+	sort.Slice(gone, func(i, j int) bool { return gone[i].Name < gone[j].Name })
+
+	return append(deltas, gone...)
+}
+
+// --------------------------------------------------
+
+// Delta is a difference of money and count between base and current periods.
+type Delta struct {
+	Name         string
+	Base         Money
+	Current      Money
+	BaseCount    int
+	CurrentCount int
+	// Total is true for sum of all sections, which is never new or gone.
+	Total bool
+}
+
+func (self *Delta) Diff() Money {
+	return self.Current.Sub(self.Base)
+}
+
+// Percent returns difference in percents of base money and false if base money
+// is zero.
+func (self *Delta) Percent() (float64, bool) {
+	if self.Base.IsZero() {
+		return 0, false
+	}
+	return self.Diff().Float() / self.Base.Float() * 100, true
+}
+
+// New returns true for sections and items, which didn't exist in base period.
+func (self *Delta) New() bool {
+	return !self.Total && self.BaseCount == 0
+}
+
+// Gone returns true for sections and items, which disappeared in current
+// period.
+func (self *Delta) Gone() bool {
+	return !self.Total && self.CurrentCount == 0
+}
+
+type SectionDelta struct {
+	Delta
+	// Level is a depth of the section, like Section.Level.
+	Level int
+	Items []*Delta
+}
+
+func newSectionDelta(base, cur *Section) *SectionDelta {
+	d := &SectionDelta{}
+	baseItems := map[string]*SectionItem{}
+	curItems := map[string]*SectionItem{}
+
+	if base != nil {
+		d.Name, d.Level = base.Name(), base.Level()
+		d.Base, d.BaseCount = base.Money(), base.Count()
+		baseItems = base.items
+	}
+	if cur != nil {
+		d.Name, d.Level = cur.Name(), cur.Level()
+		d.Current, d.CurrentCount = cur.Money(), cur.Count()
+		curItems = cur.items
+	}
+
+	for name, item := range curItems {
+		delta := &Delta{
+			Name: name, Current: item.Money(), CurrentCount: item.Count(),
+		}
+		if baseItem, ok := baseItems[name]; ok {
+			delta.Base, delta.BaseCount = baseItem.Money(), baseItem.Count()
+		}
+		d.Items = append(d.Items, delta)
+	}
+	for name, item := range baseItems {
+		if _, ok := curItems[name]; !ok {
+			d.Items = append(d.Items, &Delta{
+				Name: name, Base: item.Money(), BaseCount: item.Count(),
+			})
+		}
+	}
+
+	// biggest changes first
+	sort.Slice(d.Items, func(i, j int) bool {
+		diff1, diff2 := d.Items[i].Diff().Abs(), d.Items[j].Diff().Abs()
+		if c := diff1.Cmp(diff2); c != 0 {
+			return c > 0
+		}
+		return d.Items[i].Name < d.Items[j].Name
+	})
+	return d
+}
+
+// --------------------------------------------------
+
+// WriteText writes table of sections and their items with absolute and
+// relative differences.
+func (self *Comparison) WriteText(w io.Writer) error {
+	fmt.Fprintf(w, "Base: %s - %s, current: %s - %s\n\n",
+		self.base.BeginDateString(), self.base.EndDateString(),
+		self.cur.BeginDateString(), self.cur.EndDateString())
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+
+	names := []string{"Expenses"}
+	for _, sect := range slices.Concat(self.sections, self.incomeSections) {
+		indent := strings.Repeat("  ", sect.Level)
+		names = append(names, indent+sect.Name)
+		for _, item := range sect.Items {
+			names = append(names, indent+"  "+item.Name)
+		}
+	}
+	name := padNames(names)
+
+	writeDeltaBlock(tw, name, "Expenses", self.sections, self.base.Money(),
+		self.cur.Money())
+	if len(self.incomeSections) > 0 {
+		fmt.Fprintln(tw, "\t\t\t\t\t")
+		writeDeltaBlock(tw, name, "Income", self.incomeSections,
+			self.base.Income(), self.cur.Income())
+	}
+
+	if err := tw.Flush(); err != nil {
+		return fmt.Errorf("write comparison: %w", err)
+	}
+	return nil
+}
+
+func writeDeltaBlock(w io.Writer, name func(string) string, title string,
+	sections []*SectionDelta, base, cur Money,
+) {
+	fmt.Fprintln(w, name(title)+"\tBase\tCurrent\tDiff\t%\t")
+	for _, sect := range sections {
+		indent := strings.Repeat("  ", sect.Level)
+		writeDelta(w, name(indent+sect.Name), &sect.Delta)
+		for _, item := range sect.Items {
+			writeDelta(w, name(indent+"  "+item.Name), item)
+		}
+	}
+
+	sum := Delta{Base: base, Current: cur, Total: true}
+	writeDelta(w, name("Sum"), &sum)
+}
+
+func writeDelta(w io.Writer, name string, d *Delta) {
+	var percent string
+	switch {
+	case d.New():
+		percent = "new"
+	case d.Gone():
+		percent = "gone"
+	default:
+		if p, ok := d.Percent(); ok {
+			percent = fmt.Sprintf("%+.1f%%", p)
+		}
+	}
+	fmt.Fprintf(w, "%s\t%.02f\t%.02f\t%+.02f\t%s\t\n", name, d.Base, d.Current,
+		d.Diff(), percent)
+}
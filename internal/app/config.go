@@ -2,16 +2,47 @@ package app
 
 import (
 	"fmt"
+	"math"
 	"os"
+	"slices"
+	"strings"
+	"time"
 
 	"gopkg.in/yaml.v3"
 )
 
+const defaultUncategorized = "Uncategorized"
+
 type Config struct {
-	Sections []*SectionConfig
-	Template string
+	Sections      []*SectionConfig
+	Template      string
+	Uncategorized string
+
+	// Currency is a reporting currency. Money in other currencies is converted
+	// into it using Rates and RatesFile.
+	Currency  string
+	Rates     map[string]string
+	RatesFile string `yaml:"ratesFile"`
+
+	// CSV describes format of input CSV files and Profiles contains named
+	// formats, like exports of other banks.
+	CSV      *CSVConfig `yaml:"csv"`
+	Profiles map[string]*CSVConfig
+
+	// Fingerprint lists fields for finding duplicates of records without
+	// transaction ID.
+	Fingerprint []string
+
+	Fetch FetchConfig
+	// Store is a directory of local archive of imported payments.
+	Store string
+	// OverridesFile is a YAML file with overrides of single payments.
+	OverridesFile string `yaml:"overrides"`
 
-	sectionIndex map[string]*SectionConfig
+	sectionIndex  map[string]*SectionConfig
+	rates         *Rates
+	overrides     []*Override
+	overridesById map[string][]*Override
 }
 
 type SectionConfig struct {
@@ -20,6 +51,14 @@ type SectionConfig struct {
 	Order        int
 	Skip         bool
 	SkipPerMonth bool `yaml:"skipPerMonth"`
+	Income       bool
+	Budget       *BudgetConfig
+	Anomaly      *AnomalyConfig
+	// Sections are subsections, which inherit skip, skipPerMonth and income
+	// from this section. Their money rolls up into this section.
+	Sections []*SectionConfig
+
+	parent *SectionConfig
 }
 
 func LoadConfig(path string) (*Config, error) {
@@ -32,6 +71,7 @@ func LoadConfig(path string) (*Config, error) {
 	dec := yaml.NewDecoder(file)
 	cfg := &Config{
 		sectionIndex: make(map[string]*SectionConfig),
+		rates:        NewRates(),
 	}
 	if err := dec.Decode(cfg); err != nil {
 		return nil, fmt.Errorf("yaml decode %q: %w", path, err)
@@ -45,28 +85,232 @@ func LoadConfig(path string) (*Config, error) {
 }
 
 func (self *Config) compile() error {
-	for _, sect := range self.Sections {
-		self.sectionIndex[sect.Name] = sect
-		for i, rule := range sect.Rules {
-			if err := rule.Compile(sect.Name, i); err != nil {
-				return err
-			}
+	err := self.WalkSections(func(sect *SectionConfig) error {
+		return self.compileSection(sect)
+	})
+	if err != nil {
+		return err
+	}
+
+	self.compileUncategorized()
+	if err := self.compileCSV(); err != nil {
+		return err
+	} else if err := self.compileFingerprint(); err != nil {
+		return err
+	} else if err := self.Fetch.compile(); err != nil {
+		return err
+	} else if err := self.compileStore(); err != nil {
+		return err
+	} else if err := self.compileRates(); err != nil {
+		return err
+	} else if err := self.compileBudgets(); err != nil {
+		return err
+	} else if err := self.compileSplits(); err != nil {
+		return err
+	}
+	return self.compileOverrides()
+}
+
+// parseConfigMoney parses amount of money from config. Like exchange rates, it
+// accepts both decimal separators.
+func parseConfigMoney(s, currency string) (Money, error) {
+	return ParseMoney(strings.ReplaceAll(s, ",", "."), ".", currency)
+}
+
+// WalkSections calls fn for every section and its subsections, depth first in
+// order of config.
+func (self *Config) WalkSections(fn func(sect *SectionConfig) error) error {
+	return walkSections(self.Sections, nil, fn)
+}
+
+func walkSections(sections []*SectionConfig, parent *SectionConfig,
+	fn func(sect *SectionConfig) error,
+) error {
+	for _, sect := range sections {
+		sect.parent = parent
+		if err := fn(sect); err != nil {
+			return err
+		} else if err := walkSections(sect.Sections, sect, fn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (self *Config) compileSection(sect *SectionConfig) error {
+	if parent := sect.parent; parent != nil {
+		if sect.Income && !parent.Income {
+			return fmt.Errorf("config: income section %q inside of expense section %q",
+				sect.Name, parent.Name)
+		}
+		sect.Income = parent.Income
+		sect.Skip = sect.Skip || parent.Skip
+		sect.SkipPerMonth = sect.SkipPerMonth || parent.SkipPerMonth
+	}
+
+	if sect.Anomaly != nil {
+		if err := sect.Anomaly.compile(); err != nil {
+			return fmt.Errorf("config: section %q: %w", sect.Name, err)
+		}
+	}
+
+	if _, ok := self.sectionIndex[sect.Name]; ok {
+		return fmt.Errorf("config: duplicate section name %q", sect.Name)
+	}
+	self.sectionIndex[sect.Name] = sect
+	for i, rule := range sect.Rules {
+		if err := rule.Compile(sect.Name, i); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (self *Config) compileCSV() error {
+	if self.CSV == nil {
+		self.CSV = defaultCSVConfig()
+	}
+	if err := self.CSV.compile("csv"); err != nil {
+		return err
+	}
+
+	for name, profile := range self.Profiles {
+		if profile == nil {
+			return fmt.Errorf("config: empty profile %q", name)
+		} else if err := profile.compile("profile " + name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// CSVProfile returns named CSV format from profiles or default format for empty
+// name.
+func (self *Config) CSVProfile(name string) (*CSVConfig, error) {
+	if name == "" {
+		return self.CSV, nil
+	} else if profile, ok := self.Profiles[name]; ok {
+		return profile, nil
+	}
+	return nil, fmt.Errorf("unknown CSV profile %q", name)
+}
+
+func (self *Config) compileUncategorized() {
+	if self.Uncategorized == "" {
+		self.Uncategorized = defaultUncategorized
+	}
+
+	if _, ok := self.sectionIndex[self.Uncategorized]; !ok {
+		// synthetic section, which isn't matched by any rule and always goes last
+		self.sectionIndex[self.Uncategorized] = &SectionConfig{
+			Name:  self.Uncategorized,
+			Order: math.MaxInt,
 		}
 	}
+}
+
+// synthetic reports whether sect is synthetic uncategorized section, which
+// isn't in the tree of sections and takes payments of both directions.
+func (self *Config) synthetic(sect *SectionConfig) bool {
+	return sect.parent == nil && !slices.Contains(self.Sections, sect)
+}
+
+func (self *Config) compileStore() error {
+	if self.Store == "" {
+		return nil
+	}
+
+	store, err := expandHomeDir(self.Store)
+	if err != nil {
+		return fmt.Errorf("expand home dir in %q: %w", self.Store, err)
+	}
+	self.Store = store
 	return nil
 }
 
+func (self *Config) compileRates() error {
+	self.Currency = strings.ToUpper(self.Currency)
+	for currency, rate := range self.Rates {
+		if err := self.rates.SetFixed(currency, rate); err != nil {
+			return fmt.Errorf("config rates: %w", err)
+		}
+	}
+
+	if self.RatesFile == "" {
+		return nil
+	}
+
+	path, err := expandHomeDir(self.RatesFile)
+	if err != nil {
+		return fmt.Errorf("expand home dir in %q: %w", self.RatesFile, err)
+	}
+	return self.rates.LoadFile(path)
+}
+
+// Convert returns money converted into reporting currency, using exchange
+// rate on given date. Money is returned as is, if reporting currency isn't
+// configured.
+func (self *Config) Convert(m Money, date time.Time) (Money, error) {
+	if self.Currency == "" || m.Currency() == "" || m.Currency() == self.Currency {
+		return m, nil
+	}
+
+	rate, ok := self.rates.Rate(m.Currency(), date)
+	if !ok {
+		return m, fmt.Errorf("no exchange rate of %s on %s", m.Currency(),
+			date.Format("2006-01-02"))
+	}
+	return m.Convert(rate, self.Currency), nil
+}
+
 func (self *Config) FindSection(rec Record) (string, string, error) {
-	for _, sect := range self.Sections {
-		for _, rule := range sect.Rules {
+	m, err := self.MatchSection(rec)
+	if err != nil {
+		return "", "", err
+	}
+	return m.Section, m.Key, nil
+}
+
+// MatchSection returns first rule, which matches given record. Returned Match
+// is empty if nothing matched. Rules of subsections are checked before rules of
+// their parent section, so parent rules catch what left. Override of the record
+// has priority over rules.
+func (self *Config) MatchSection(rec Record) (Match, error) {
+	m, err := matchSections(self.Sections, rec)
+	if err != nil {
+		return m, err
+	} else if rec.override != nil {
+		return rec.override.match(m, rec)
+	}
+	return m, nil
+}
+
+func matchSections(sections []*SectionConfig, rec Record) (Match, error) {
+	for _, sect := range sections {
+		if sect.Income == rec.Out() {
+			continue
+		}
+
+		if m, err := matchSections(sect.Sections, rec); err != nil || m.Found() {
+			return m, err
+		}
+
+		for i, rule := range sect.Rules {
 			if key, err := rule.ExtractKey(rec); err != nil {
-				return "", "", err
+				return Match{}, err
 			} else if key != "" {
-				return sect.Name, key, nil
+				tags, err := rule.ExtractTags(rec)
+				if err != nil {
+					return Match{}, err
+				}
+				return Match{
+					Section: sect.Name, Rule: i, Key: key, Tags: tags,
+					rule: rule, split: rule.Split,
+				}, nil
 			}
 		}
 	}
-	return "", "", nil
+	return Match{}, nil
 }
 
 func (self *Config) SkipFromSum(sectName string) bool {
@@ -78,3 +322,29 @@ func (self *Config) SkipPerMonth(sectName string) bool {
 	sect := self.sectionIndex[sectName]
 	return sect.SkipPerMonth
 }
+
+// --------------------------------------------------
+
+type Match struct {
+	Section string
+	Rule    int
+	Key     string
+	Tags    []string
+	// Override is an override, which changed matched section or key.
+	Override *Override
+
+	rule  *SectionRule
+	split []*SplitPart
+}
+
+func (self *Match) Found() bool {
+	return self.Section != "" && self.Key != ""
+}
+
+// Criteria returns names of criteria of matched rule, like account or re.
+func (self *Match) Criteria() []string {
+	if self.rule == nil {
+		return nil
+	}
+	return self.rule.Criteria()
+}
@@ -0,0 +1,95 @@
+package app
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// loadTestConfig writes YAML into a temporary file and loads config from it.
+func loadTestConfig(t *testing.T, s string) (*Config, error) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "fio.yaml")
+	if err := os.WriteFile(path, []byte(s), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return LoadConfig(path)
+}
+
+// mustLoadConfig is like loadTestConfig, but fails the test on error.
+func mustLoadConfig(t *testing.T, s string) *Config {
+	t.Helper()
+	cfg, err := loadTestConfig(t, s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cfg
+}
+
+func TestLoadConfig_duplicateSections(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     string
+		wantErr string
+	}{
+		{
+			name: "siblings",
+			cfg: `
+sections:
+  - name: Food
+  - name: Food
+`,
+			wantErr: `duplicate section name "Food"`,
+		},
+		{
+			name: "subsection with name of parent",
+			cfg: `
+sections:
+  - name: Home
+    sections:
+      - name: Home
+`,
+			wantErr: `duplicate section name "Home"`,
+		},
+		{
+			name: "nested in different parents",
+			cfg: `
+sections:
+  - name: Home
+    sections:
+      - name: Energy
+  - name: Car
+    sections:
+      - name: Fuel
+        sections:
+          - name: Energy
+`,
+			wantErr: `duplicate section name "Energy"`,
+		},
+		{
+			name: "unique",
+			cfg: `
+sections:
+  - name: Home
+    sections:
+      - name: Energy
+  - name: Car
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := loadTestConfig(t, tt.cfg)
+			switch {
+			case tt.wantErr == "" && err != nil:
+				t.Errorf("LoadConfig: %v", err)
+			case tt.wantErr != "" && err == nil:
+				t.Errorf("LoadConfig: no error, want %q", tt.wantErr)
+			case tt.wantErr != "" && !strings.Contains(err.Error(), tt.wantErr):
+				t.Errorf("LoadConfig: error %q, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,151 @@
+package app
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Path returns names of parent sections and this section, like "Home /
+// Energy".
+func (self *SectionConfig) Path() string {
+	if self.parent == nil {
+		return self.Name
+	}
+	return self.parent.Path() + " / " + self.Name
+}
+
+// CheckRuleSection returns error, if rules for payments of given direction
+// can't be added to section: it's uncategorized section or a section of other
+// direction.
+func (self *Config) CheckRuleSection(sectName string, income bool) error {
+	if sectName == self.Uncategorized {
+		return fmt.Errorf("section %q is for payments, which don't match any rule",
+			sectName)
+	} else if sect, ok := self.sectionIndex[sectName]; ok && sect.Income != income {
+		return fmt.Errorf("section %q has different direction", sectName)
+	}
+	return nil
+}
+
+// AddRule compiles rule and appends it to rules of section. The section is
+// created on top level, if it doesn't exist.
+func (self *Config) AddRule(sectName string, income bool, rule *SectionRule,
+) error {
+	if err := self.CheckRuleSection(sectName, income); err != nil {
+		return err
+	}
+
+	sect, ok := self.sectionIndex[sectName]
+	if !ok {
+		sect = &SectionConfig{Name: sectName, Income: income}
+		self.Sections = append(self.Sections, sect)
+		self.sectionIndex[sectName] = sect
+	}
+
+	if err := rule.Compile(sectName, len(sect.Rules)); err != nil {
+		return err
+	}
+	sect.Rules = append(sect.Rules, rule)
+	return nil
+}
+
+// AppendRule appends rule to section in config file, like AddRule. Comments
+// of the config file are preserved, but it's reformatted.
+func AppendRule(path, sectName string, income bool, rule *SectionRule) error {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("read config: %w", err)
+	}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal(b, &doc); err != nil {
+		return fmt.Errorf("yaml decode %q: %w", path, err)
+	} else if len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+		return fmt.Errorf("config %q: not a mapping", path)
+	}
+
+	ruleNode := &yaml.Node{}
+	if err := ruleNode.Encode(rule); err != nil {
+		return fmt.Errorf("encode rule: %w", err)
+	}
+	ruleNode.Content = withoutEmptyValues(ruleNode.Content)
+
+	sections := mappingValue(doc.Content[0], "sections", yaml.SequenceNode)
+	if sect := findSectionNode(sections, sectName); sect != nil {
+		rules := mappingValue(sect, "rules", yaml.SequenceNode)
+		rules.Content = append(rules.Content, ruleNode)
+	} else {
+		sect := &yaml.Node{Kind: yaml.MappingNode}
+		setMappingValue(sect, "name", sectName)
+		if income {
+			setMappingValue(sect, "income", "true")
+		}
+		rules := mappingValue(sect, "rules", yaml.SequenceNode)
+		rules.Content = append(rules.Content, ruleNode)
+		sections.Content = append(sections.Content, sect)
+	}
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(&doc); err != nil {
+		return fmt.Errorf("encode config: %w", err)
+	} else if err := enc.Close(); err != nil {
+		return fmt.Errorf("encode config: %w", err)
+	}
+
+	stat, err := os.Stat(path)
+	if err != nil {
+		return fmt.Errorf("stat config: %w", err)
+	}
+	return writeFileAtomic(path, buf.Bytes(), stat.Mode().Perm())
+}
+
+// mappingValue returns value of key in mapping node. Missing value of given
+// kind is added.
+func mappingValue(node *yaml.Node, key string, kind yaml.Kind) *yaml.Node {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			v := node.Content[i+1]
+			if v.Kind != kind { // like empty value
+				*v = yaml.Node{Kind: kind}
+			}
+			return v
+		}
+	}
+
+	v := &yaml.Node{Kind: kind}
+	node.Content = append(node.Content,
+		&yaml.Node{Kind: yaml.ScalarNode, Value: key}, v)
+	return v
+}
+
+func setMappingValue(node *yaml.Node, key, value string) {
+	v := mappingValue(node, key, yaml.ScalarNode)
+	v.Value = value
+}
+
+// findSectionNode returns node of section with given name from sections node
+// or from their subsections.
+func findSectionNode(sections *yaml.Node, name string) *yaml.Node {
+	for _, sect := range sections.Content {
+		if sect.Kind != yaml.MappingNode {
+			continue
+		}
+		for i := 0; i+1 < len(sect.Content); i += 2 {
+			k, v := sect.Content[i], sect.Content[i+1]
+			switch {
+			case k.Value == "name" && v.Value == name:
+				return sect
+			case k.Value == "sections" && v.Kind == yaml.SequenceNode:
+				if found := findSectionNode(v, name); found != nil {
+					return found
+				}
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,186 @@
+package app
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const testEditConfig = `# report config
+currency: CZK
+
+sections:
+  # daily expenses
+  - name: Food
+    rules:
+      - re: '^Nákup: (ALBERT|LIDL),' # supermarkets
+  - name: Home
+    sections:
+      - name: Energy
+        rules: # empty for now
+  - name: Salary
+    income: true
+`
+
+func TestAppendRule(t *testing.T) {
+	tests := []struct {
+		name    string
+		section string
+		income  bool
+		rule    *SectionRule
+		want    string
+	}{
+		{
+			name:    "existing section",
+			section: "Food",
+			rule:    &SectionRule{Re: `^Nákup: (BILLA),`},
+			want: `# report config
+currency: CZK
+sections:
+  # daily expenses
+  - name: Food
+    rules:
+      - re: '^Nákup: (ALBERT|LIDL),' # supermarkets
+      - re: '^Nákup: (BILLA),'
+  - name: Home
+    sections:
+      - name: Energy
+        rules: # empty for now
+  - name: Salary
+    income: true
+`,
+		},
+		{
+			name:    "subsection with empty rules",
+			section: "Energy",
+			rule:    &SectionRule{Account: "123456/0100", Vs: "42"},
+			want: `# report config
+currency: CZK
+sections:
+  # daily expenses
+  - name: Food
+    rules:
+      - re: '^Nákup: (ALBERT|LIDL),' # supermarkets
+  - name: Home
+    sections:
+      - name: Energy
+        rules: # empty for now
+          - account: 123456/0100
+            vs: "42"
+  - name: Salary
+    income: true
+`,
+		},
+		{
+			name:    "section without rules",
+			section: "Home",
+			rule:    &SectionRule{Account: "123456/0100"},
+			want: `# report config
+currency: CZK
+sections:
+  # daily expenses
+  - name: Food
+    rules:
+      - re: '^Nákup: (ALBERT|LIDL),' # supermarkets
+  - name: Home
+    sections:
+      - name: Energy
+        rules: # empty for now
+    rules:
+      - account: 123456/0100
+  - name: Salary
+    income: true
+`,
+		},
+		{
+			name:    "new income section",
+			section: "Rent",
+			income:  true,
+			rule:    &SectionRule{Account: "777777/0300"},
+			want: `# report config
+currency: CZK
+sections:
+  # daily expenses
+  - name: Food
+    rules:
+      - re: '^Nákup: (ALBERT|LIDL),' # supermarkets
+  - name: Home
+    sections:
+      - name: Energy
+        rules: # empty for now
+  - name: Salary
+    income: true
+  - name: Rent
+    income: true
+    rules:
+      - account: 777777/0300
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "fio.yaml")
+			if err := os.WriteFile(path, []byte(testEditConfig), 0o600); err != nil {
+				t.Fatal(err)
+			}
+			if err := AppendRule(path, tt.section, tt.income, tt.rule); err != nil {
+				t.Fatalf("AppendRule: %v", err)
+			}
+
+			b, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			} else if got := string(b); got != tt.want {
+				t.Errorf("AppendRule() =\n%s\nwant\n%s", got, tt.want)
+			}
+
+			cfg, err := LoadConfig(path)
+			if err != nil {
+				t.Fatalf("LoadConfig after AppendRule: %v", err)
+			}
+			sect := cfg.sectionIndex[tt.section]
+			if sect == nil || sect.Income != tt.income {
+				t.Fatalf("section %q not found or has different direction",
+					tt.section)
+			}
+			rule := sect.Rules[len(sect.Rules)-1]
+			if rule.Re != tt.rule.Re || rule.Account != tt.rule.Account ||
+				rule.Vs != tt.rule.Vs {
+				t.Errorf("last rule of %q = %+v, want %+v", tt.section, rule, tt.rule)
+			}
+		})
+	}
+}
+
+func TestConfig_CheckRuleSection(t *testing.T) {
+	cfg := mustLoadConfig(t, testEditConfig)
+	tests := []struct {
+		section string
+		income  bool
+		wantErr bool
+	}{
+		{section: "Food"},
+		{section: "Energy"},
+		{section: "New"},
+		{section: "New", income: true},
+		{section: "Salary", income: true},
+		{section: "Salary", wantErr: true},
+		{section: "Food", income: true, wantErr: true},
+		{section: "Uncategorized", wantErr: true},
+		{section: "Uncategorized", income: true, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		err := cfg.CheckRuleSection(tt.section, tt.income)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("CheckRuleSection(%q, %v) = %v, want error %v", tt.section,
+				tt.income, err, tt.wantErr)
+		}
+	}
+
+	rule := &SectionRule{Account: "123456/0100"}
+	if err := cfg.AddRule("Uncategorized", false, rule); err == nil {
+		t.Error("AddRule to uncategorized section: no error")
+	}
+}
@@ -0,0 +1,128 @@
+package app
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"unicode/utf8"
+)
+
+// Logical fields of a record, which are mapped to CSV columns.
+const (
+	fieldId       = "id"
+	fieldDate     = "date"
+	fieldAmount   = "amount"
+	fieldCurrency = "currency"
+	fieldAccount  = "account"
+	fieldBankCode = "bankCode"
+	fieldMessage  = "message"
+	fieldNote     = "note"
+	fieldVs       = "vs"
+	fieldKs       = "ks"
+	fieldSs       = "ss"
+	fieldType     = "type"
+)
+
+// fioColumns maps logical fields to columns of CSV export from Fio banka.
+var fioColumns = map[string]string{
+	fieldId:       "ID pohybu",
+	fieldDate:     "Datum",
+	fieldAmount:   "Objem",
+	fieldCurrency: "Měna",
+	fieldAccount:  "Protiúčet",
+	fieldBankCode: "Kód banky",
+	fieldMessage:  "Zpráva pro příjemce",
+	fieldNote:     "Poznámka",
+	fieldVs:       "VS",
+	fieldKs:       "KS",
+	fieldSs:       "SS",
+	fieldType:     "Typ",
+}
+
+func defaultCSVConfig() *CSVConfig {
+	return &CSVConfig{
+		Separator:  ";",
+		Decimal:    ",",
+		DateLayout: "02.01.2006",
+		Columns:    fioColumns,
+	}
+}
+
+// CSVConfig describes format of CSV file. Unset properties default to CSV
+// export from Fio banka.
+type CSVConfig struct {
+	Separator  string
+	Decimal    string
+	DateLayout string `yaml:"dateLayout"`
+	// Columns maps logical fields, like date or amount, to column names.
+	Columns map[string]string
+
+	comma rune
+}
+
+func (self *CSVConfig) compile(name string) error {
+	def := defaultCSVConfig()
+	if self.Separator == "" {
+		self.Separator = def.Separator
+	}
+	if self.Decimal == "" {
+		self.Decimal = def.Decimal
+	}
+	if self.DateLayout == "" {
+		self.DateLayout = def.DateLayout
+	}
+
+	columns := make(map[string]string, len(def.Columns))
+	for field, col := range def.Columns {
+		columns[field] = col
+	}
+	for field, col := range self.Columns {
+		if _, ok := def.Columns[field]; !ok {
+			return fmt.Errorf("config %s: unknown field %q", name, field)
+		} else if col == "" && (field == fieldDate || field == fieldAmount) {
+			return fmt.Errorf("config %s: field %q required", name, field)
+		} else if col == "" {
+			delete(columns, field) // field isn't in the file
+		} else {
+			columns[field] = col
+		}
+	}
+	self.Columns = columns
+
+	if err := self.checkColumns(); err != nil {
+		return fmt.Errorf("config %s: %w", name, err)
+	}
+
+	if utf8.RuneCountInString(self.Separator) != 1 {
+		return fmt.Errorf("config %s: separator must be one character, got %q",
+			name, self.Separator)
+	}
+	self.comma, _ = utf8.DecodeRuneInString(self.Separator)
+
+	return nil
+}
+
+// checkColumns returns error, if two fields are mapped to the same column.
+func (self *CSVConfig) checkColumns() error {
+	fields := slices.Sorted(maps.Keys(self.Columns))
+	seen := make(map[string]string, len(fields))
+	for _, field := range fields {
+		col := self.Columns[field]
+		if prev, ok := seen[col]; ok {
+			return fmt.Errorf(
+				"fields %q and %q mapped to the same column %q, set one of them to \"\"",
+				prev, field, col)
+		}
+		seen[col] = field
+	}
+	return nil
+}
+
+// fieldsByColumn returns reverse mapping of column names to logical fields.
+func (self *CSVConfig) fieldsByColumn() map[string]string {
+	fields := make(map[string]string, len(self.Columns))
+	for field, col := range self.Columns {
+		fields[col] = field
+	}
+	return fields
+}
@@ -0,0 +1,139 @@
+package app
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCSVConfig_compile(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     CSVConfig
+		wantErr string
+	}{
+		{name: "default"},
+		{
+			name: "remapped column",
+			cfg: CSVConfig{Columns: map[string]string{
+				fieldNote: "Zpráva pro příjemce", fieldMessage: "Poznámka",
+			}},
+		},
+		{
+			name: "dropped field",
+			cfg: CSVConfig{Columns: map[string]string{
+				fieldNote: "Zpráva pro příjemce", fieldMessage: "",
+			}},
+		},
+		{
+			name: "duplicate column",
+			cfg: CSVConfig{Columns: map[string]string{
+				fieldNote: "Zpráva pro příjemce",
+			}},
+			wantErr: `fields "message" and "note" mapped to the same column`,
+		},
+		{
+			name:    "unknown field",
+			cfg:     CSVConfig{Columns: map[string]string{"memo": "Memo"}},
+			wantErr: `unknown field "memo"`,
+		},
+		{
+			name:    "required field",
+			cfg:     CSVConfig{Columns: map[string]string{fieldDate: ""}},
+			wantErr: `field "date" required`,
+		},
+		{
+			name:    "long separator",
+			cfg:     CSVConfig{Separator: ";;"},
+			wantErr: "separator must be one character",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.cfg.compile("test")
+			switch {
+			case tt.wantErr == "" && err != nil:
+				t.Fatal(err)
+			case tt.wantErr != "" && err == nil:
+				t.Fatalf("expected error %q", tt.wantErr)
+			case tt.wantErr != "" && !strings.Contains(err.Error(), tt.wantErr):
+				t.Fatalf("got error %q, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestParser_profile(t *testing.T) {
+	format := &CSVConfig{
+		Separator:  ",",
+		Decimal:    ".",
+		DateLayout: "2006-01-02",
+		Columns: map[string]string{
+			fieldDate:     "Datum splatnosti",
+			fieldAmount:   "Částka",
+			fieldAccount:  "Protiúčet a kód banky",
+			fieldBankCode: "",
+			fieldMessage:  "Popis pro mě",
+			fieldNote:     "Zpráva pro příjemce",
+			fieldVs:       "VS",
+		},
+	}
+	if err := format.compile("profile kb"); err != nil {
+		t.Fatal(err)
+	}
+
+	const input = "\ufeffDatum splatnosti,Částka,Měna,Protiúčet a kód banky,Popis pro mě,Zpráva pro příjemce,VS\n" +
+		"2026-03-02,-1234.50,czk,123456/0100,Rent,March,111\n" +
+		`2026-03-05,"1,5",CZK,,Refund,,` + "\n"
+	p, err := NewParser(strings.NewReader(input), format)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rec, err := p.Next()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "line 2: 2026-03-02 -1234.50 \"March\""
+	if got := rec.String(); got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+	if rec.AccountId() != "123456/0100" || rec.Vs() != "111" ||
+		rec.Currency() != "CZK" {
+		t.Errorf("got account %q, VS %q, currency %q", rec.AccountId(), rec.Vs(),
+			rec.Currency())
+	}
+
+	if _, err := p.Next(); err == nil || !strings.Contains(err.Error(), "line 3") {
+		t.Errorf("got error %v, want error of amount on line 3", err)
+	}
+}
+
+func TestParser_default(t *testing.T) {
+	format := defaultCSVConfig()
+	if err := format.compile("csv"); err != nil {
+		t.Fatal(err)
+	}
+
+	const input = `"Datum";"Objem";"Měna";"Protiúčet";"Kód banky";"VS";"Zpráva pro příjemce";"Poznámka";"Typ";"ID pohybu"` + "\n" +
+		`"02.03.2026";"-1 250,10";"CZK";"";"";"";"";"Nákup: ALBERT, Praha";"Platba kartou";"1002"` + "\n"
+	p, err := NewParser(strings.NewReader(input), format)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rec, err := p.Next()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "line 2: 2026-03-02 -1250.10 \"Nákup: ALBERT, Praha\""
+	if got := rec.String(); got != want || rec.Id() != "1002" {
+		t.Errorf("got %s with id %q, want %s with id 1002", got, rec.Id(), want)
+	}
+
+	if rec, err := p.Next(); err != nil {
+		t.Fatal(err)
+	} else if rec.Valid() {
+		t.Errorf("got %s after the last line", rec.String())
+	}
+}
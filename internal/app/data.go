@@ -8,7 +8,19 @@ import (
 
 func NewReportData(cfg *Config) *ReportData {
 	return &ReportData{
-		sections: make(map[string]*Section),
+		sections:       make(map[string]*Section),
+		incomeSections: make(map[string]*Section),
+
+		currencies:       make(map[string]*SectionItem),
+		incomeCurrencies: make(map[string]*SectionItem),
+
+		byMonth:       make(map[string]Money),
+		incomeByMonth: make(map[string]Money),
+
+		tags:       make(map[string]*SectionItem),
+		incomeTags: make(map[string]*SectionItem),
+
+		ruleSpent: make(map[*SectionRule]Money),
 
 		cfg: cfg,
 	}
@@ -19,9 +31,29 @@ type ReportData struct {
 	endDate       time.Time
 	monthsBetween int
 
-	money    float32
-	count    int
-	sections map[string]*Section
+	money          Money
+	count          int
+	income         Money
+	incomeCount    int
+	sections       map[string]*Section
+	incomeSections map[string]*Section
+
+	// totals in original currencies, before conversion into reporting currency
+	currency         string
+	currencies       map[string]*SectionItem
+	incomeCurrencies map[string]*SectionItem
+
+	// totals per month, keyed by month like 2026-03
+	byMonth       map[string]Money
+	incomeByMonth map[string]Money
+
+	// totals per tag of matched rules
+	tags       map[string]*SectionItem
+	incomeTags map[string]*SectionItem
+
+	// money of rules with budgets and budgets of sections and rules
+	ruleSpent map[*SectionRule]Money
+	budgets   []*Budget
 
 	cfg *Config
 }
@@ -30,33 +62,225 @@ func (self *ReportData) Count() int {
 	return self.count
 }
 
-func (self *ReportData) Money() float32 {
+func (self *ReportData) Money() Money {
 	return self.money
 }
 
-func (self *ReportData) addRecord(sectName, sectKey string, rec Record) {
+func (self *ReportData) IncomeCount() int {
+	return self.incomeCount
+}
+
+func (self *ReportData) Income() Money {
+	return self.income
+}
+
+// Net returns income minus expenses.
+func (self *ReportData) Net() Money {
+	return self.income.Sub(self.money)
+}
+
+func (self *ReportData) Empty() bool {
+	return self.count == 0 && self.incomeCount == 0
+}
+
+// Currency returns reporting currency.
+func (self *ReportData) Currency() string {
+	return self.currency
+}
+
+func (self *ReportData) addRecord(m Match, rec Record) error {
+	money, err := self.convert(rec)
+	if err != nil {
+		return err
+	}
 	self.updateTimes(rec)
 
-	money := rec.Money()
-	if !self.cfg.SkipFromSum(sectName) {
+	month := monthKey(rec.Date())
+	self.addTags(m.Tags, !rec.Out(), month, money)
+
+	parts := m.parts(money)
+
+	// budget of the rule is charged by parts in section of the rule only
+	if m.rule != nil && m.rule.Budget != nil {
+		for _, part := range parts {
+			if part.section == m.Section {
+				self.ruleSpent[m.rule] = self.ruleSpent[m.rule].Add(part.money)
+			}
+		}
+	}
+
+	// payment is counted once in totals, but every part is counted in its
+	// section
+	var sum Money
+	inSum := false
+	for _, part := range parts {
+		if !self.cfg.SkipFromSum(part.section) {
+			sum = sum.Add(part.money)
+			inSum = true
+		}
+		self.addSection(part.section, !rec.Out(), month, part.money).
+			addItem(part.key, month, part.money)
+	}
+
+	if inSum {
+		self.addTotal(rec, month, sum)
+	}
+	return nil
+}
+
+func (self *ReportData) addTotal(rec Record, month string, money Money) {
+	if !rec.Out() {
+		self.incomeCount++
+		self.income = self.income.Add(money)
+		self.incomeByMonth[month] = self.incomeByMonth[month].Add(money)
+		self.addCurrency(self.incomeCurrencies, month, rec.Money())
+	} else {
 		self.count++
-		self.money += money
+		self.money = self.money.Add(money)
+		self.byMonth[month] = self.byMonth[month].Add(money)
+		self.addCurrency(self.currencies, month, rec.Money())
 	}
+}
 
-	self.addSection(sectName, money).addItem(sectKey, money)
+func (self *ReportData) convert(rec Record) (Money, error) {
+	money, err := self.cfg.Convert(rec.Money(), rec.Date())
+	if err != nil {
+		return money, fmt.Errorf("line %d: %w", rec.Line(), err)
+	}
+
+	switch {
+	case money.Currency() == "":
+	case self.currency == "":
+		self.currency = money.Currency()
+	case money.Currency() != self.currency:
+		return money, fmt.Errorf(
+			"line %d: mixed currencies %s and %s, configure reporting currency and rates",
+			rec.Line(), self.currency, money.Currency())
+	}
+	return money, nil
 }
 
-func (self *ReportData) addSection(sectName string, money float32) *Section {
-	sect := self.sections[sectName]
-	if sect == nil {
-		sect = newSection(sectName).withMonthsBetween(self.MonthsBetween).
-			withSkipFromSum(self.cfg.SkipFromSum(sectName))
-		self.sections[sectName] = sect
+func (self *ReportData) addCurrency(currencies map[string]*SectionItem,
+	month string, money Money,
+) {
+	item := currencies[money.Currency()]
+	if item == nil {
+		item = newSectionItem(money.Currency()).
+			withMonthsBetween(self.MonthsBetween)
+		currencies[money.Currency()] = item
+	}
+	item.Add(month, money)
+}
+
+func (self *ReportData) addTags(tags []string, income bool, month string,
+	money Money,
+) {
+	index := self.tags
+	if income {
+		index = self.incomeTags
+	}
+
+	for _, tag := range tags {
+		item := index[tag]
+		if item == nil {
+			item = newSectionItem(tag).withMonthsBetween(self.MonthsBetween)
+			index[tag] = item
+		}
+		item.Add(month, money)
+	}
+}
+
+// SortedTags returns totals of expenses per tag. Every payment is counted in
+// all its tags, including payments from sections skipped from sum.
+func (self *ReportData) SortedTags() []*SectionItem {
+	return sortItems(self.tags)
+}
+
+func (self *ReportData) SortedIncomeTags() []*SectionItem {
+	return sortItems(self.incomeTags)
+}
+
+// SortedCurrencies returns totals of expenses in their original currencies.
+func (self *ReportData) SortedCurrencies() []*SectionItem {
+	return sortItems(self.currencies)
+}
+
+func (self *ReportData) SortedIncomeCurrencies() []*SectionItem {
+	return sortItems(self.incomeCurrencies)
+}
+
+func (self *ReportData) addSection(sectName string, income bool, month string,
+	money Money,
+) *Section {
+	sections := self.sections
+	if income {
+		sections = self.incomeSections
+	}
+
+	sect := self.section(sections, sectName, income)
+	for s := sect; s != nil; s = s.parent {
+		s.Add(month, money)
+		if s.skipFromSum && s.parent != nil && !s.parent.skipFromSum {
+			break // money skipped from sum doesn't roll up into parent in sum
+		}
 	}
-	sect.Add(money)
 	return sect
 }
 
+// section returns existing section or creates it with all its parent sections.
+func (self *ReportData) section(sections map[string]*Section, sectName string,
+	income bool,
+) *Section {
+	if sect, ok := sections[sectName]; ok {
+		return sect
+	}
+
+	sectCfg := self.cfg.sectionIndex[sectName]
+	sect := newSection(sectName).withMonthsBetween(self.MonthsBetween).
+		withSkipFromSum(sectCfg.Skip).
+		withIncome(income).
+		withOrder(sectCfg.Order)
+	sections[sectName] = sect
+
+	if sectCfg.parent != nil {
+		parent := self.section(sections, sectCfg.parent.Name, income)
+		parent.sections[sectName] = sect
+		sect.parent = parent
+		sect.level = parent.level + 1
+	}
+	return sect
+}
+
+func monthKey(t time.Time) string {
+	return t.Format("2006-01")
+}
+
+// Months returns all months of the report, like 2026-03, including months
+// without payments.
+func (self *ReportData) Months() []string {
+	if self.beginDate.IsZero() {
+		return nil
+	}
+
+	months := make([]string, 0, self.MonthsBetween())
+	d := time.Date(self.beginDate.Year(), self.beginDate.Month(), 1, 0, 0, 0, 0,
+		time.UTC)
+	for !d.After(self.endDate) {
+		months = append(months, monthKey(d))
+		d = d.AddDate(0, 1, 0)
+	}
+	return months
+}
+
+// ByMonth returns expenses in given month, like {{.ByMonth "2026-03"}}.
+func (self *ReportData) ByMonth(month string) Money {
+	return self.byMonth[month]
+}
+
+func (self *ReportData) IncomeByMonth(month string) Money {
+	return self.incomeByMonth[month]
+}
+
 func (self *ReportData) updateTimes(rec Record) {
 	if self.beginDate.IsZero() || rec.Date().Before(self.beginDate) {
 		self.beginDate = rec.Date()
@@ -66,22 +290,43 @@ func (self *ReportData) updateTimes(rec Record) {
 	}
 }
 
+// SortedSections returns top level expense sections, ordered by config and
+// money. Subsections are returned by SortedSections of every section.
 func (self *ReportData) SortedSections() []*Section {
-	sections := make([]*Section, 0, len(self.sections))
-	for _, sect := range self.sections {
-		sections = append(sections, sect)
+	return sortSections(self.sections, true)
+}
+
+func (self *ReportData) SortedIncomeSections() []*Section {
+	return sortSections(self.incomeSections, true)
+}
+
+func sortSections(index map[string]*Section, topLevel bool) []*Section {
+	sections := make([]*Section, 0, len(index))
+	for _, sect := range index {
+		if !topLevel || sect.parent == nil {
+			sections = append(sections, sect)
+		}
 	}
 	sort.Slice(sections, func(i, j int) bool {
-		order1 := self.cfg.sectionIndex[sections[i].Name()].Order
-		order2 := self.cfg.sectionIndex[sections[j].Name()].Order
-		if order1 == order2 {
-			return sections[i].Money() >= sections[j].Money()
+		if order1, order2 := sections[i].order, sections[j].order; order1 != order2 {
+			return order1 < order2
+		} else if c := sections[i].Money().Cmp(sections[j].Money()); c != 0 {
+			return c > 0
 		}
-		return order1 < order2
+		return sections[i].Name() < sections[j].Name()
 	})
 	return sections
 }
 
+// eachSection calls fn for every section and its subsections, depth first in
+// sorted order.
+func eachSection(sections []*Section, fn func(sect *Section)) {
+	for _, sect := range sections {
+		fn(sect)
+		eachSection(sect.SortedSections(), fn)
+	}
+}
+
 func (self *ReportData) updateMonthsBetween() {
 	beginYear, beginMonth, _ := self.beginDate.Date()
 	endYear, endMonth, _ := self.endDate.Date()
@@ -104,15 +349,89 @@ func (self *ReportData) EndDateString() string {
 	return self.endDate.Format("2006-01-02")
 }
 
-func (self *ReportData) PerMonthString(format string) string {
+// perMonth returns average expenses per month and false, if there isn't enough
+// payments or months for averaging.
+func (self *ReportData) perMonth() (Money, bool) {
 	if self.Count() < 2 || self.MonthsBetween() < 2 {
-		return ""
+		return Money{}, false
+	}
+	return self.Money().Div(self.MonthsBetween()), true
+}
+
+func (self *ReportData) PerMonthString(format string) string {
+	if m, ok := self.perMonth(); ok {
+		return fmt.Sprintf(format, m)
+	}
+	return ""
+}
+
+func (self *ReportData) incomePerMonth() (Money, bool) {
+	if self.IncomeCount() < 2 || self.MonthsBetween() < 2 {
+		return Money{}, false
+	}
+	return self.Income().Div(self.MonthsBetween()), true
+}
+
+func (self *ReportData) IncomePerMonthString(format string) string {
+	if m, ok := self.incomePerMonth(); ok {
+		return fmt.Sprintf(format, m)
 	}
-	return fmt.Sprintf(format, self.Money()/float32(self.MonthsBetween()))
+	return ""
 }
 
 func (self *ReportData) finish() {
 	self.updateMonthsBetween()
+	self.updateBudgets()
+}
+
+// updateBudgets prorates budgets of sections and rules for months of the report.
+func (self *ReportData) updateBudgets() {
+	self.budgets = self.budgets[:0]
+	months := self.MonthsBetween()
+	_ = self.cfg.WalkSections(func(sectCfg *SectionConfig) error {
+		sections := self.sections
+		if sectCfg.Income {
+			sections = self.incomeSections
+		}
+		sect := sections[sectCfg.Name]
+
+		if sectCfg.Budget != nil {
+			var spent Money
+			if sect != nil {
+				spent = sect.Money()
+			}
+			budget := newBudget(sectCfg.Name, sectCfg.Budget.prorate(months), spent)
+			self.budgets = append(self.budgets, budget)
+			if sect != nil {
+				sect.budget = budget
+			}
+		}
+
+		for _, rule := range sectCfg.Rules {
+			if rule.Budget != nil {
+				self.budgets = append(self.budgets, newBudget(
+					sectCfg.Name+": "+rule.name(), rule.Budget.prorate(months),
+					self.ruleSpent[rule]))
+			}
+		}
+		return nil
+	})
+}
+
+// Budgets returns budgets of sections and rules in order of config.
+func (self *ReportData) Budgets() []*Budget {
+	return self.budgets
+}
+
+// OverBudget returns budgets, which are exceeded.
+func (self *ReportData) OverBudget() []*Budget {
+	var over []*Budget
+	for _, b := range self.budgets {
+		if b.Over() {
+			over = append(over, b)
+		}
+	}
+	return over
 }
 
 func (self *ReportData) MonthsBetween() int {
@@ -123,20 +442,30 @@ func (self *ReportData) MonthsBetween() int {
 
 func newSection(name string) *Section {
 	return &Section{
-		name:  name,
-		items: make(map[string]*SectionItem),
+		name:     name,
+		byMonth:  make(map[string]Money),
+		items:    make(map[string]*SectionItem),
+		sections: make(map[string]*Section),
 	}
 }
 
 type Section struct {
-	name  string
-	money float32
-	count int
+	name    string
+	money   Money
+	count   int
+	byMonth map[string]Money
 
 	monthsBetween func() int
 	skipFromSum   bool
+	income        bool
+	budget        *Budget
+	order         int
 
 	items map[string]*SectionItem
+
+	parent   *Section
+	level    int
+	sections map[string]*Section
 }
 
 func (self *Section) withMonthsBetween(m func() int) *Section {
@@ -149,11 +478,21 @@ func (self *Section) withSkipFromSum(v bool) *Section {
 	return self
 }
 
+func (self *Section) withIncome(v bool) *Section {
+	self.income = v
+	return self
+}
+
+func (self *Section) withOrder(order int) *Section {
+	self.order = order
+	return self
+}
+
 func (self *Section) Name() string {
 	return self.name
 }
 
-func (self *Section) Money() float32 {
+func (self *Section) Money() Money {
 	return self.money
 }
 
@@ -161,56 +500,111 @@ func (self *Section) Count() int {
 	return self.count
 }
 
+func (self *Section) Income() bool {
+	return self.income
+}
+
 func (self *Section) MonthsBetween() int {
 	return self.monthsBetween()
 }
 
-func (self *Section) Add(money float32) {
+func (self *Section) Add(month string, money Money) {
 	self.count++
-	self.money += money
+	self.money = self.money.Add(money)
+	self.byMonth[month] = self.byMonth[month].Add(money)
+}
+
+// ByMonth returns money in given month, like {{.ByMonth "2026-03"}}.
+func (self *Section) ByMonth(month string) Money {
+	return self.byMonth[month]
 }
 
 func (self *Section) SortedItems() []*SectionItem {
-	sortedItems := make([]*SectionItem, 0, len(self.items))
-	for _, item := range self.items {
+	return sortItems(self.items)
+}
+
+// SortedSections returns subsections, ordered by config and money.
+func (self *Section) SortedSections() []*Section {
+	return sortSections(self.sections, false)
+}
+
+// Level returns depth of the section: 0 for top level sections, 1 for their
+// subsections and so on.
+func (self *Section) Level() int {
+	return self.level
+}
+
+// Path returns names of all parent sections and this section, like "Home /
+// Energy".
+func (self *Section) Path() string {
+	if self.parent == nil {
+		return self.name
+	}
+	return self.parent.Path() + " / " + self.name
+}
+
+func sortItems(items map[string]*SectionItem) []*SectionItem {
+	sortedItems := make([]*SectionItem, 0, len(items))
+	for _, item := range items {
 		sortedItems = append(sortedItems, item)
 	}
 	sort.Slice(sortedItems, func(i, j int) bool {
-		return sortedItems[i].Money() >= sortedItems[j].Money()
+		if c := sortedItems[i].Money().Cmp(sortedItems[j].Money()); c != 0 {
+			return c > 0
+		}
+		return sortedItems[i].Name() < sortedItems[j].Name()
 	})
 	return sortedItems
 }
 
-func (self *Section) addItem(sectKey string, money float32) *SectionItem {
+func (self *Section) addItem(sectKey, month string, money Money) *SectionItem {
 	item := self.items[sectKey]
 	if item == nil {
 		item = newSectionItem(sectKey).withMonthsBetween(self.monthsBetween).
 			withSkipFromSum(self.skipFromSum)
 		self.items[sectKey] = item
 	}
-	item.Add(money)
+	item.Add(month, money)
 	return item
 }
 
-func (self *Section) PerMonthString(format string) string {
+func (self *Section) perMonth() (Money, bool) {
 	if self.skipFromSum || self.count < 2 || self.MonthsBetween() < 2 {
-		return ""
+		return Money{}, false
 	}
-	return fmt.Sprintf(format, self.Money()/float32(self.MonthsBetween()))
+	return self.Money().Div(self.MonthsBetween()), true
+}
+
+func (self *Section) PerMonthString(format string) string {
+	if m, ok := self.perMonth(); ok {
+		return fmt.Sprintf(format, m)
+	}
+	return ""
+}
+
+func (self *Section) SkipFromSum() bool {
+	return self.skipFromSum
+}
+
+// Budget returns budget of the section or nil, like {{with .Budget}}.
+func (self *Section) Budget() *Budget {
+	return self.budget
 }
 
 // --------------------------------------------------
 
 func newSectionItem(name string) *SectionItem {
 	return &SectionItem{
-		name: name,
+		name:    name,
+		byMonth: make(map[string]Money),
 	}
 }
 
 type SectionItem struct {
-	name  string
-	money float32
-	count int
+	name    string
+	money   Money
+	count   int
+	byMonth map[string]Money
 
 	monthsBetween func() int
 	skipFromSum   bool
@@ -230,7 +624,7 @@ func (self *SectionItem) Name() string {
 	return self.name
 }
 
-func (self *SectionItem) Money() float32 {
+func (self *SectionItem) Money() Money {
 	return self.money
 }
 
@@ -238,18 +632,30 @@ func (self *SectionItem) Count() int {
 	return self.count
 }
 
-func (self *SectionItem) Add(money float32) {
+func (self *SectionItem) Add(month string, money Money) {
 	self.count++
-	self.money += money
+	self.money = self.money.Add(money)
+	self.byMonth[month] = self.byMonth[month].Add(money)
+}
+
+func (self *SectionItem) ByMonth(month string) Money {
+	return self.byMonth[month]
 }
 
 func (self *SectionItem) MonthsBetween() int {
 	return self.monthsBetween()
 }
 
-func (self *SectionItem) PerMonthString(format string) string {
+func (self *SectionItem) perMonth() (Money, bool) {
 	if self.skipFromSum || self.count < 2 || self.MonthsBetween() < 2 {
-		return ""
+		return Money{}, false
+	}
+	return self.Money().Div(self.MonthsBetween()), true
+}
+
+func (self *SectionItem) PerMonthString(format string) string {
+	if m, ok := self.perMonth(); ok {
+		return fmt.Sprintf(format, m)
 	}
-	return fmt.Sprintf(format, self.Money()/float32(self.MonthsBetween()))
+	return ""
 }
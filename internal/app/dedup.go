@@ -0,0 +1,77 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+)
+
+var defaultFingerprint = []string{"date", "amount", "account", "note"}
+
+// fingerprintFields are fields of a record, which can be used for finding
+// duplicates of records without transaction ID.
+var fingerprintFields = map[string]func(rec *Record) string{
+	"date":    func(rec *Record) string { return rec.Date().Format("2006-01-02") },
+	"amount":  func(rec *Record) string { return rec.Amount().String() + rec.Currency() },
+	"account": func(rec *Record) string { return rec.AccountId() },
+	"note":    func(rec *Record) string { return rec.Note() },
+	"vs":      func(rec *Record) string { return rec.Vs() },
+	"ks":      func(rec *Record) string { return rec.Ks() },
+	"ss":      func(rec *Record) string { return rec.Ss() },
+	"type":    func(rec *Record) string { return rec.Type() },
+}
+
+func (self *Config) compileFingerprint() error {
+	if len(self.Fingerprint) == 0 {
+		self.Fingerprint = defaultFingerprint
+	}
+
+	for _, name := range self.Fingerprint {
+		if _, ok := fingerprintFields[name]; !ok {
+			return fmt.Errorf("config fingerprint: unknown field %q", name)
+		}
+	}
+	return nil
+}
+
+// RecordKey returns transaction ID of the record or its fingerprint, if it
+// doesn't have ID.
+func (self *Config) RecordKey(rec Record) string {
+	if rec.Id() != "" {
+		return "id:" + rec.Id()
+	}
+
+	values := make([]string, len(self.Fingerprint))
+	for i, name := range self.Fingerprint {
+		values[i] = fingerprintFields[name](&rec)
+	}
+	return "fp:" + strings.Join(values, "\x00")
+}
+
+// --------------------------------------------------
+
+func newDedup() *dedup {
+	return &dedup{seen: make(map[string]int)}
+}
+
+// dedup finds records, which were seen in previous files. Equal records inside
+// of one file aren't duplicates, like two equal payments in one shop in one
+// day, so it keeps max number of equal records per file.
+type dedup struct {
+	seen    map[string]int
+	cur     map[string]int
+	skipped int
+}
+
+func (self *dedup) nextFile() {
+	self.cur = make(map[string]int)
+}
+
+func (self *dedup) duplicate(key string) bool {
+	self.cur[key]++
+	if n := self.cur[key]; n > self.seen[key] {
+		self.seen[key] = n
+		return false
+	}
+	self.skipped++
+	return true
+}
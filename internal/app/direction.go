@@ -0,0 +1,43 @@
+package app
+
+import "fmt"
+
+type Direction int
+
+const (
+	DirectionOut Direction = iota
+	DirectionIn
+	DirectionBoth
+)
+
+func ParseDirection(s string) (Direction, error) {
+	switch s {
+	case "", "out":
+		return DirectionOut, nil
+	case "in":
+		return DirectionIn, nil
+	case "both":
+		return DirectionBoth, nil
+	}
+	return DirectionOut, fmt.Errorf("unknown direction %q, expected out, in or both", s)
+}
+
+func (self Direction) String() string {
+	switch self {
+	case DirectionIn:
+		return "in"
+	case DirectionBoth:
+		return "both"
+	}
+	return "out"
+}
+
+func (self Direction) Match(rec Record) bool {
+	switch self {
+	case DirectionIn:
+		return !rec.Out()
+	case DirectionBoth:
+		return true
+	}
+	return rec.Out()
+}
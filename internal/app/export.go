@@ -0,0 +1,163 @@
+package app
+
+import (
+	"archive/zip"
+	"encoding/csv"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+var exportHeader = []string{
+	"Direction", "Section", "Item", "Money", "Count", "Per month",
+}
+
+// exportRow is a row of table with aggregated results. Rows with empty item
+// are subtotals of sections. Subsections are named by their path, like "Home /
+// Energy".
+type exportRow struct {
+	direction string
+	section   string
+	item      string
+	money     Money
+	count     int
+	perMonth  Money
+	ok        bool
+}
+
+func (self *exportRow) strings() []string {
+	perMonth := ""
+	if self.ok {
+		perMonth = self.perMonth.String()
+	}
+	return []string{
+		self.direction, self.section, self.item, self.money.String(),
+		strconv.Itoa(self.count), perMonth,
+	}
+}
+
+func (self *ReportData) exportRows() []*exportRow {
+	var rows []*exportRow
+	addSections := func(direction string, sections []*Section) {
+		eachSection(sections, func(sect *Section) {
+			for _, item := range sect.SortedItems() {
+				perMonth, ok := item.perMonth()
+				rows = append(rows, &exportRow{
+					direction, sect.Path(), item.Name(), item.Money(), item.Count(),
+					perMonth, ok,
+				})
+			}
+			perMonth, ok := sect.perMonth()
+			rows = append(rows, &exportRow{
+				direction, sect.Path(), "", sect.Money(), sect.Count(), perMonth, ok,
+			})
+		})
+	}
+
+	addSections("expense", self.SortedSections())
+	addSections("income", self.SortedIncomeSections())
+	return rows
+}
+
+// WriteCSV writes a row for every item of every section, followed by subtotal
+// row of the section with empty item.
+func (self *ReportData) WriteCSV(w io.Writer) error {
+	csvWriter := csv.NewWriter(w)
+	if err := csvWriter.Write(exportHeader); err != nil {
+		return fmt.Errorf("write csv: %w", err)
+	}
+
+	for _, row := range self.exportRows() {
+		if err := csvWriter.Write(row.strings()); err != nil {
+			return fmt.Errorf("write csv: %w", err)
+		}
+	}
+
+	csvWriter.Flush()
+	if err := csvWriter.Error(); err != nil {
+		return fmt.Errorf("write csv: %w", err)
+	}
+	return nil
+}
+
+// --------------------------------------------------
+
+// xlsxFiles are static parts of minimal XLSX file with one sheet.
+var xlsxFiles = []struct{ name, body string }{
+	{"[Content_Types].xml", xml.Header + `<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+		`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+		`<Default Extension="xml" ContentType="application/xml"/>` +
+		`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>` +
+		`<Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>` +
+		`</Types>`},
+	{"_rels/.rels", xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>` +
+		`</Relationships>`},
+	{"xl/workbook.xml", xml.Header + `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">` +
+		`<sheets><sheet name="Report" sheetId="1" r:id="rId1"/></sheets>` +
+		`</workbook>`},
+	{"xl/_rels/workbook.xml.rels", xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/>` +
+		`</Relationships>`},
+}
+
+// WriteXLSX writes the same table as WriteCSV, but as XLSX spreadsheet with
+// numeric cells.
+func (self *ReportData) WriteXLSX(w io.Writer) error {
+	z := zip.NewWriter(w)
+	for _, f := range xlsxFiles {
+		if err := writeZipFile(z, f.name, f.body); err != nil {
+			return err
+		}
+	}
+
+	if err := writeZipFile(z, "xl/worksheets/sheet1.xml", self.xlsxSheet()); err != nil {
+		return err
+	} else if err := z.Close(); err != nil {
+		return fmt.Errorf("write xlsx: %w", err)
+	}
+	return nil
+}
+
+func writeZipFile(z *zip.Writer, name, body string) error {
+	f, err := z.Create(name)
+	if err != nil {
+		return fmt.Errorf("write xlsx %q: %w", name, err)
+	} else if _, err := io.WriteString(f, body); err != nil {
+		return fmt.Errorf("write xlsx %q: %w", name, err)
+	}
+	return nil
+}
+
+func (self *ReportData) xlsxSheet() string {
+	var b strings.Builder
+	b.WriteString(xml.Header)
+	b.WriteString(`<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`)
+
+	writeRow := func(n int, cells []string, numeric func(col int) bool) {
+		fmt.Fprintf(&b, `<row r="%d">`, n)
+		for col, v := range cells {
+			ref := string(rune('A'+col)) + strconv.Itoa(n)
+			switch {
+			case v == "":
+			case numeric(col):
+				fmt.Fprintf(&b, `<c r="%s"><v>%s</v></c>`, ref, v)
+			default:
+				fmt.Fprintf(&b, `<c r="%s" t="inlineStr"><is><t>`, ref)
+				_ = xml.EscapeText(&b, []byte(v))
+				b.WriteString(`</t></is></c>`)
+			}
+		}
+		b.WriteString(`</row>`)
+	}
+
+	writeRow(1, exportHeader, func(int) bool { return false })
+	for i, row := range self.exportRows() {
+		writeRow(i+2, row.strings(), func(col int) bool { return col >= 3 })
+	}
+
+	b.WriteString(`</sheetData></worksheet>`)
+	return b.String()
+}
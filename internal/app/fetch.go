@@ -0,0 +1,279 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+)
+
+const (
+	defaultFioApiURL = "https://fioapi.fio.cz/v1/rest"
+	fioTokenEnv      = "FIO_TOKEN"
+	fetchStateFile   = ".fio-fetch"
+
+	// fetchDays is a number of days for the first download. Fio API allows
+	// downloading older transactions only after additional authorization.
+	fetchDays = 89
+)
+
+type FetchConfig struct {
+	// Token is a token of Fio API. FIO_TOKEN environment variable has priority
+	// over it.
+	Token string
+	// Archive is a directory, where downloaded statements are saved.
+	Archive string
+	URL     string `yaml:"url"`
+}
+
+func (self *FetchConfig) compile() error {
+	if token := os.Getenv(fioTokenEnv); token != "" {
+		self.Token = token
+	}
+
+	if self.URL == "" {
+		self.URL = defaultFioApiURL
+	}
+
+	if self.Archive != "" {
+		archive, err := expandHomeDir(self.Archive)
+		if err != nil {
+			return fmt.Errorf("expand home dir in %q: %w", self.Archive, err)
+		}
+		self.Archive = archive
+	}
+	return nil
+}
+
+// --------------------------------------------------
+
+func NewClient(baseURL, token string) *Client {
+	return &Client{
+		baseURL: baseURL,
+		token:   token,
+		client:  http.DefaultClient,
+	}
+}
+
+// Client downloads statements from Fio API.
+type Client struct {
+	baseURL string
+	token   string
+	client  *http.Client
+}
+
+// Periods downloads statement with transactions between given dates.
+func (self *Client) Periods(ctx context.Context, from, to time.Time,
+) (*fioStatement, error) {
+	u, err := url.JoinPath(self.baseURL, "periods", self.token,
+		from.Format("2006-01-02"), to.Format("2006-01-02"), "transactions.json")
+	if err != nil {
+		return nil, fmt.Errorf("fio api url: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, fmt.Errorf("fio api request: %w", err)
+	}
+
+	resp, err := self.client.Do(req)
+	if err != nil {
+		// don't leak token from url into error message
+		if urlErr := (*url.Error)(nil); errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+		return nil, fmt.Errorf("fio api: %w", err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusConflict:
+		return nil, errors.New("fio api: too many requests, try again in 30 seconds")
+	default:
+		_, _ = io.Copy(io.Discard, resp.Body)
+		return nil, fmt.Errorf("fio api: unexpected status %q", resp.Status)
+	}
+
+	return decodeFioStatement(resp.Body)
+}
+
+// --------------------------------------------------
+
+func NewFetcher(cfg *FetchConfig) *Fetcher {
+	return &Fetcher{
+		cfg:    cfg,
+		client: NewClient(cfg.URL, cfg.Token),
+		now:    time.Now,
+	}
+}
+
+// Fetcher downloads new transactions from Fio API into archive directory. It
+// remembers ID and date of last downloaded transaction and next time downloads
+// transactions after it.
+type Fetcher struct {
+	cfg    *FetchConfig
+	client *Client
+
+	fromDate time.Time
+	now      func() time.Time
+}
+
+type fetchState struct {
+	LastId   int64  `json:"lastId"`
+	LastDate string `json:"lastDate"`
+}
+
+func (self *Fetcher) WithFromDate(t time.Time) *Fetcher {
+	self.fromDate = t
+	return self
+}
+
+// Fetch downloads new transactions and returns path of saved statement and
+// number of new transactions in it. Nothing is saved, if there are no new
+// transactions.
+func (self *Fetcher) Fetch(ctx context.Context) (string, int, error) {
+	if self.cfg.Token == "" {
+		return "", 0, fmt.Errorf("fetch: token not defined in config or %s",
+			fioTokenEnv)
+	} else if self.cfg.Archive == "" {
+		return "", 0, errors.New("fetch: archive not defined in config")
+	}
+
+	state, err := self.loadState()
+	if err != nil {
+		return "", 0, err
+	}
+
+	from, err := self.from(state)
+	if err != nil {
+		return "", 0, err
+	}
+
+	statement, err := self.client.Periods(ctx, from, self.now())
+	if err != nil {
+		return "", 0, err
+	}
+
+	n, err := self.skipKnown(statement, state)
+	if err != nil || n == 0 {
+		return "", 0, err
+	}
+
+	path, err := self.save(statement, state)
+	if err != nil {
+		return "", 0, err
+	}
+	return path, n, self.saveState(state)
+}
+
+func (self *Fetcher) from(state *fetchState) (time.Time, error) {
+	switch {
+	case !self.fromDate.IsZero():
+		return self.fromDate, nil
+	case state.LastDate != "":
+		d, err := time.Parse("2006-01-02", state.LastDate)
+		if err != nil {
+			return d, fmt.Errorf("fetch state: %w", err)
+		}
+		return d, nil
+	}
+	return self.now().AddDate(0, 0, -fetchDays), nil
+}
+
+// skipKnown removes transactions, which were downloaded before, and updates
+// state by the last transaction.
+func (self *Fetcher) skipKnown(statement *fioStatement, state *fetchState,
+) (int, error) {
+	transactions := statement.transactions()
+	newTransactions := transactions[:0]
+	lastId := state.LastId
+
+	for i, t := range transactions {
+		columns, err := t.columns()
+		if err != nil {
+			return 0, fmt.Errorf("transaction %d: %w", i+1, err)
+		}
+		rec, err := fioApiRecord(columns, i+1, fioJSONFormat)
+		if err != nil {
+			return 0, err
+		}
+
+		id, err := strconv.ParseInt(rec.Id(), 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("transaction %d: parse id %q: %w", i+1, rec.Id(), err)
+		} else if id <= lastId {
+			continue
+		}
+
+		newTransactions = append(newTransactions, t)
+		state.LastId = max(state.LastId, id)
+		if date := rec.Date().Format("2006-01-02"); date > state.LastDate {
+			state.LastDate = date
+		}
+	}
+
+	statement.AccountStatement.TransactionList.Transaction = newTransactions
+	return len(newTransactions), nil
+}
+
+func (self *Fetcher) save(statement *fioStatement, state *fetchState,
+) (string, error) {
+	name := fmt.Sprintf("fio-%s-%d.json", self.now().Format("20060102"),
+		state.LastId)
+	path := filepath.Join(self.cfg.Archive, name)
+
+	b, err := json.Marshal(statement)
+	if err != nil {
+		return "", fmt.Errorf("encode statement: %w", err)
+	}
+	return path, writeFileAtomic(path, b, 0o600)
+}
+
+func (self *Fetcher) statePath() string {
+	return filepath.Join(self.cfg.Archive, fetchStateFile)
+}
+
+func (self *Fetcher) loadState() (*fetchState, error) {
+	state := &fetchState{}
+	b, err := os.ReadFile(self.statePath())
+	if errors.Is(err, os.ErrNotExist) {
+		return state, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("read fetch state: %w", err)
+	}
+
+	if err := json.Unmarshal(b, state); err != nil {
+		return nil, fmt.Errorf("decode fetch state %q: %w", self.statePath(), err)
+	}
+	return state, nil
+}
+
+func (self *Fetcher) saveState(state *fetchState) error {
+	b, err := json.Marshal(state)
+	if err != nil {
+		return fmt.Errorf("encode fetch state: %w", err)
+	}
+	return writeFileAtomic(self.statePath(), b, 0o600)
+}
+
+func writeFileAtomic(path string, b []byte, perm os.FileMode) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return fmt.Errorf("create dir: %w", err)
+	}
+
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, b, perm); err != nil {
+		return fmt.Errorf("write %q: %w", tmp, err)
+	} else if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("rename %q: %w", tmp, err)
+	}
+	return nil
+}
@@ -0,0 +1,225 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testFetchToken = "secret-token"
+
+// newFioServer returns stand-in of Fio API, which serves recorded statement
+// and saves path of every request into paths.
+func newFioServer(t *testing.T, status int, paths *[]string) *httptest.Server {
+	t.Helper()
+	statement, err := os.ReadFile(filepath.Join("testdata", "statement.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	srv := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			if paths != nil {
+				*paths = append(*paths, r.URL.Path)
+			}
+			if status != http.StatusOK {
+				w.WriteHeader(status)
+				return
+			}
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write(statement)
+		}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func newTestFetcher(url, archive string) *Fetcher {
+	fetcher := NewFetcher(&FetchConfig{
+		Token:   testFetchToken,
+		Archive: archive,
+		URL:     url,
+	})
+	fetcher.now = func() time.Time {
+		return time.Date(2026, 4, 30, 12, 0, 0, 0, time.UTC)
+	}
+	return fetcher
+}
+
+func readFetchState(t *testing.T, archive string) fetchState {
+	t.Helper()
+	b, err := os.ReadFile(filepath.Join(archive, fetchStateFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var state fetchState
+	if err := json.Unmarshal(b, &state); err != nil {
+		t.Fatal(err)
+	}
+	return state
+}
+
+func readStatementIds(t *testing.T, path string) []string {
+	t.Helper()
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	src, err := NewJSONParser(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var ids []string
+	for {
+		rec, err := src.Next()
+		if err != nil {
+			t.Fatal(err)
+		} else if !rec.Valid() {
+			return ids
+		}
+		ids = append(ids, rec.Id())
+	}
+}
+
+func TestFetcher_Fetch_first(t *testing.T) {
+	var paths []string
+	srv := newFioServer(t, http.StatusOK, &paths)
+	archive := t.TempDir()
+
+	path, n, err := newTestFetcher(srv.URL, archive).Fetch(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	wantPath := "/periods/" + testFetchToken +
+		"/2026-01-31/2026-04-30/transactions.json"
+	if len(paths) != 1 || paths[0] != wantPath {
+		t.Errorf("requests %v, want %q", paths, wantPath)
+	}
+
+	if n != 3 {
+		t.Errorf("got %d transactions, want 3", n)
+	}
+	if want := filepath.Join(archive, "fio-20260430-1010.json"); path != want {
+		t.Errorf("saved into %q, want %q", path, want)
+	}
+	if ids := readStatementIds(t, path); strings.Join(ids, ",") != "1004,1009,1010" {
+		t.Errorf("saved transactions %v, want 1004, 1009 and 1010", ids)
+	}
+
+	state := readFetchState(t, archive)
+	if state.LastId != 1010 || state.LastDate != "2026-04-11" {
+		t.Errorf("state %+v, want last id 1010 on 2026-04-11", state)
+	}
+}
+
+func TestFetcher_Fetch_incremental(t *testing.T) {
+	var paths []string
+	srv := newFioServer(t, http.StatusOK, &paths)
+	archive := t.TempDir()
+
+	statePath := filepath.Join(archive, fetchStateFile)
+	err := os.WriteFile(statePath,
+		[]byte(`{"lastId":1004,"lastDate":"2026-04-03"}`), 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fetcher := newTestFetcher(srv.URL, archive)
+	path, n, err := fetcher.Fetch(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	wantPath := "/periods/" + testFetchToken +
+		"/2026-04-03/2026-04-30/transactions.json"
+	if len(paths) != 1 || paths[0] != wantPath {
+		t.Errorf("requests %v, want %q", paths, wantPath)
+	}
+
+	if n != 2 {
+		t.Errorf("got %d transactions, want 2", n)
+	}
+	if ids := readStatementIds(t, path); strings.Join(ids, ",") != "1009,1010" {
+		t.Errorf("saved transactions %v, want 1009 and 1010", ids)
+	}
+
+	state := readFetchState(t, archive)
+	if state.LastId != 1010 || state.LastDate != "2026-04-11" {
+		t.Errorf("state %+v, want last id 1010 on 2026-04-11", state)
+	}
+
+	// nothing new for the next time
+	path, n, err = fetcher.Fetch(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	} else if path != "" || n != 0 {
+		t.Errorf("got %d transactions into %q, want nothing", n, path)
+	}
+
+	entries, err := os.ReadDir(archive)
+	if err != nil {
+		t.Fatal(err)
+	} else if len(entries) != 2 {
+		t.Errorf("got %d files in archive, want statement and state", len(entries))
+	}
+}
+
+func TestFetcher_Fetch_tooManyRequests(t *testing.T) {
+	srv := newFioServer(t, http.StatusConflict, nil)
+	archive := t.TempDir()
+
+	_, _, err := newTestFetcher(srv.URL, archive).Fetch(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "too many requests") {
+		t.Fatalf("got error %v, want too many requests", err)
+	} else if strings.Contains(err.Error(), testFetchToken) {
+		t.Errorf("error %q contains token", err)
+	}
+
+	if _, err := os.Stat(filepath.Join(archive, fetchStateFile)); err == nil {
+		t.Error("state saved after failed request")
+	}
+}
+
+func TestFetcher_Fetch_config(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     FetchConfig
+		wantErr string
+	}{
+		{
+			name:    "no token",
+			cfg:     FetchConfig{Archive: t.TempDir()},
+			wantErr: "token not defined",
+		},
+		{
+			name:    "no archive",
+			cfg:     FetchConfig{Token: testFetchToken},
+			wantErr: "archive not defined",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var paths []string
+			srv := newFioServer(t, http.StatusOK, &paths)
+			tt.cfg.URL = srv.URL
+
+			_, _, err := NewFetcher(&tt.cfg).Fetch(context.Background())
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("got error %v, want %q", err, tt.wantErr)
+			} else if len(paths) != 0 {
+				t.Errorf("unexpected requests %v", paths)
+			}
+		})
+	}
+}
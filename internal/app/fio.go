@@ -0,0 +1,181 @@
+package app
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+)
+
+// fioApiFields maps column IDs of Fio API statements to logical fields.
+var fioApiFields = map[int]string{
+	22: fieldId,
+	0:  fieldDate,
+	1:  fieldAmount,
+	14: fieldCurrency,
+	2:  fieldAccount,
+	3:  fieldBankCode,
+	4:  fieldKs,
+	5:  fieldVs,
+	6:  fieldSs,
+	7:  fieldNote,
+	16: fieldMessage,
+	8:  fieldType,
+}
+
+// fioApiComment is ID of "Komentář" column, used as a note, if "Uživatelská
+// identifikace" is empty.
+const fioApiComment = 25
+
+var (
+	fioJSONFormat = &CSVConfig{Decimal: ".", DateLayout: "2006-01-02-0700"}
+	fioXMLFormat  = &CSVConfig{Decimal: ".", DateLayout: "2006-01-02Z07:00"}
+)
+
+// fioApiRecord parses record from columns of Fio API statement, keyed by column
+// ID.
+func fioApiRecord(columns map[int]string, line int, format *CSVConfig,
+) (rec Record, err error) {
+	fields := make(map[string]string, len(fioApiFields))
+	for id, field := range fioApiFields {
+		fields[field] = columns[id]
+	}
+	if fields[fieldNote] == "" {
+		fields[fieldNote] = columns[fioApiComment]
+	}
+	err = rec.Parse(fields, line, format)
+	return
+}
+
+// --------------------------------------------------
+
+// fioStatement is a statement in JSON format from Fio API.
+type fioStatement struct {
+	AccountStatement struct {
+		Info            json.RawMessage `json:"info"`
+		TransactionList struct {
+			Transaction []fioTransaction `json:"transaction"`
+		} `json:"transactionList"`
+	} `json:"accountStatement"`
+}
+
+type fioTransaction map[string]*fioColumn
+
+type fioColumn struct {
+	Value json.RawMessage `json:"value"`
+	Name  string          `json:"name"`
+	Id    int             `json:"id"`
+}
+
+func (self *fioColumn) String() (string, error) {
+	switch {
+	case len(self.Value) == 0 || bytes.Equal(self.Value, []byte("null")):
+		return "", nil
+	case self.Value[0] == '"':
+		var s string
+		if err := json.Unmarshal(self.Value, &s); err != nil {
+			return "", fmt.Errorf("column %d: %w", self.Id, err)
+		}
+		return s, nil
+	}
+	return string(self.Value), nil
+}
+
+func (self fioTransaction) columns() (map[int]string, error) {
+	columns := make(map[int]string, len(self))
+	for _, col := range self {
+		if col == nil {
+			continue
+		}
+		s, err := col.String()
+		if err != nil {
+			return nil, err
+		}
+		columns[col.Id] = s
+	}
+	return columns, nil
+}
+
+func (self *fioStatement) transactions() []fioTransaction {
+	return self.AccountStatement.TransactionList.Transaction
+}
+
+func decodeFioStatement(r io.Reader) (*fioStatement, error) {
+	dec := json.NewDecoder(r)
+	var statement fioStatement
+	if err := dec.Decode(&statement); err != nil {
+		return nil, fmt.Errorf("decode json statement: %w", err)
+	}
+	return &statement, nil
+}
+
+func NewJSONParser(r io.Reader) (*JSONParser, error) {
+	statement, err := decodeFioStatement(r)
+	if err != nil {
+		return nil, err
+	}
+	return &JSONParser{transactions: statement.transactions()}, nil
+}
+
+// JSONParser reads records from statement in JSON format from Fio API.
+type JSONParser struct {
+	transactions []fioTransaction
+	next         int
+}
+
+func (self *JSONParser) Next() (rec Record, err error) {
+	if self.next >= len(self.transactions) {
+		return
+	}
+	t := self.transactions[self.next]
+	self.next++
+
+	columns, err := t.columns()
+	if err != nil {
+		return rec, fmt.Errorf("transaction %d: %w", self.next, err)
+	}
+	return fioApiRecord(columns, self.next, fioJSONFormat)
+}
+
+// --------------------------------------------------
+
+type fioXMLStatement struct {
+	XMLName      xml.Name            `xml:"AccountStatement"`
+	Transactions []fioXMLTransaction `xml:"TransactionList>Transaction"`
+}
+
+type fioXMLTransaction struct {
+	Columns []struct {
+		Id    int    `xml:"id,attr"`
+		Value string `xml:",chardata"`
+	} `xml:",any"`
+}
+
+func NewXMLParser(r io.Reader) (*XMLParser, error) {
+	var statement fioXMLStatement
+	if err := xml.NewDecoder(r).Decode(&statement); err != nil {
+		return nil, fmt.Errorf("decode xml statement: %w", err)
+	}
+	return &XMLParser{transactions: statement.Transactions}, nil
+}
+
+// XMLParser reads records from statement in XML format from Fio API.
+type XMLParser struct {
+	transactions []fioXMLTransaction
+	next         int
+}
+
+func (self *XMLParser) Next() (rec Record, err error) {
+	if self.next >= len(self.transactions) {
+		return
+	}
+	t := &self.transactions[self.next]
+	self.next++
+
+	columns := make(map[int]string, len(t.Columns))
+	for _, col := range t.Columns {
+		columns[col.Id] = col.Value
+	}
+	return fioApiRecord(columns, self.next, fioXMLFormat)
+}
@@ -0,0 +1,169 @@
+package app
+
+import (
+	_ "embed"
+	"fmt"
+	"html/template"
+	"io"
+	"math"
+	"strings"
+)
+
+//go:embed report.html
+var htmlTemplate string
+
+var chartColors = []string{
+	"#4e79a7", "#f28e2b", "#e15759", "#76b7b2", "#59a14f", "#edc948",
+	"#b07aa1", "#ff9da7", "#9c755f", "#bab0ac",
+}
+
+const (
+	pieRadius    = 100
+	trendWidth   = 640
+	trendHeight  = 240
+	trendPadding = 40
+)
+
+type htmlReport struct {
+	*ReportData
+
+	Pie   []*pieSlice
+	Trend *trendChart
+}
+
+type pieSlice struct {
+	Name    string
+	Money   Money
+	Percent float64
+	Path    string
+	Color   string
+}
+
+type trendChart struct {
+	Width, Height int
+	Expenses      string
+	Income        string
+	Points        []*trendPoint
+	Max           Money
+}
+
+type trendPoint struct {
+	Month      string
+	X          float64
+	Y, IncomeY float64
+	Money      Money
+	Income     Money
+}
+
+// WriteHTML writes report as self-contained HTML page with inline SVG charts.
+func (self *ReportData) WriteHTML(w io.Writer) error {
+	tmpl, err := template.New("report.html").Funcs(template.FuncMap{
+		"money": func(m Money) string { return m.Grouped() },
+		"color": func(i int) string { return chartColors[i%len(chartColors)] },
+	}).Parse(htmlTemplate)
+	if err != nil {
+		return fmt.Errorf("parse html template: %w", err)
+	}
+
+	r := &htmlReport{
+		ReportData: self,
+		Pie:        self.pieChart(),
+		Trend:      self.trendChart(),
+	}
+	if err := tmpl.Execute(w, r); err != nil {
+		return fmt.Errorf("exec html template: %w", err)
+	}
+	return nil
+}
+
+// pieChart returns slices of expense sections, which aren't skipped from sum.
+func (self *ReportData) pieChart() []*pieSlice {
+	var slices []*pieSlice
+	total := self.Money().Float()
+	if total <= 0 {
+		return nil
+	}
+
+	angle := -math.Pi / 2
+	for _, sect := range self.SortedSections() {
+		if sect.SkipFromSum() {
+			continue
+		}
+		part := sect.Money().Float() / total
+		next := angle + part*2*math.Pi
+		slices = append(slices, &pieSlice{
+			Name:    sect.Name(),
+			Money:   sect.Money(),
+			Percent: part * 100,
+			Path:    arcPath(angle, next, part),
+			Color:   chartColors[len(slices)%len(chartColors)],
+		})
+		angle = next
+	}
+	return slices
+}
+
+func arcPath(from, to, part float64) string {
+	if part >= 0.9999 {
+		return fmt.Sprintf("M 0 %d A %d %d 0 1 1 0 %d A %d %d 0 1 1 0 %d Z",
+			-pieRadius, pieRadius, pieRadius, pieRadius, pieRadius, pieRadius,
+			-pieRadius)
+	}
+
+	largeArc := 0
+	if part > 0.5 {
+		largeArc = 1
+	}
+	return fmt.Sprintf("M 0 0 L %.2f %.2f A %d %d 0 %d 1 %.2f %.2f Z",
+		pieRadius*math.Cos(from), pieRadius*math.Sin(from), pieRadius, pieRadius,
+		largeArc, pieRadius*math.Cos(to), pieRadius*math.Sin(to))
+}
+
+func (self *ReportData) trendChart() *trendChart {
+	months := self.Months()
+	if len(months) == 0 {
+		return nil
+	}
+
+	var maxMoney Money
+	for _, month := range months {
+		for _, m := range []Money{self.ByMonth(month), self.IncomeByMonth(month)} {
+			if m.Cmp(maxMoney) > 0 {
+				maxMoney = m
+			}
+		}
+	}
+
+	chart := &trendChart{Width: trendWidth, Height: trendHeight, Max: maxMoney}
+	step := float64(trendWidth - 2*trendPadding)
+	if len(months) > 1 {
+		step /= float64(len(months) - 1)
+	}
+	y := func(m Money) float64 {
+		if maxMoney.IsZero() {
+			return trendHeight - trendPadding
+		}
+		h := float64(trendHeight - 2*trendPadding)
+		return trendHeight - trendPadding - m.Float()/maxMoney.Float()*h
+	}
+
+	var expenses, income strings.Builder
+	for i, month := range months {
+		p := &trendPoint{
+			Month:  month,
+			X:      trendPadding + float64(i)*step,
+			Money:  self.ByMonth(month),
+			Income: self.IncomeByMonth(month),
+		}
+		p.Y, p.IncomeY = y(p.Money), y(p.Income)
+		chart.Points = append(chart.Points, p)
+		fmt.Fprintf(&expenses, "%.1f,%.1f ", p.X, p.Y)
+		fmt.Fprintf(&income, "%.1f,%.1f ", p.X, p.IncomeY)
+	}
+
+	chart.Expenses = strings.TrimSpace(expenses.String())
+	if self.IncomeCount() > 0 {
+		chart.Income = strings.TrimSpace(income.String())
+	}
+	return chart
+}
@@ -0,0 +1,89 @@
+package app
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"text/tabwriter"
+	"unicode/utf8"
+)
+
+// WriteMatrix writes a table with sections as rows and months as columns.
+func (self *ReportData) WriteMatrix(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	months := self.Months()
+	sections, incomeSections := self.SortedSections(), self.SortedIncomeSections()
+
+	names := []string{"Expenses"}
+	eachSection(slices.Concat(sections, incomeSections), func(sect *Section) {
+		names = append(names, matrixName(sect))
+	})
+	name := padNames(names)
+
+	self.writeMatrixBlock(tw, name, "Expenses", months, sections, self.ByMonth,
+		self.Money())
+	if len(incomeSections) > 0 {
+		fmt.Fprintln(tw, strings.Repeat("\t", len(months)+2))
+		self.writeMatrixBlock(tw, name, "Income", months, incomeSections,
+			self.IncomeByMonth, self.Income())
+		cells := []string{name("Net")}
+		for _, month := range months {
+			net := self.IncomeByMonth(month).Sub(self.ByMonth(month))
+			cells = append(cells, net.String())
+		}
+		cells = append(cells, self.Net().String())
+		fmt.Fprintln(tw, strings.Join(cells, "\t")+"\t")
+	}
+
+	if err := tw.Flush(); err != nil {
+		return fmt.Errorf("write matrix: %w", err)
+	}
+	return nil
+}
+
+func (self *ReportData) writeMatrixBlock(w io.Writer, name func(string) string,
+	title string, months []string, sections []*Section,
+	byMonth func(string) Money, sum Money,
+) {
+	fmt.Fprintln(w, name(title)+"\t"+strings.Join(months, "\t")+"\tTotal\t")
+
+	eachSection(sections, func(sect *Section) {
+		cells := []string{name(matrixName(sect))}
+		for _, month := range months {
+			cells = append(cells, sect.ByMonth(month).String())
+		}
+		cells = append(cells, sect.Money().String())
+		fmt.Fprintln(w, strings.Join(cells, "\t")+"\t")
+	})
+
+	cells := []string{name("Sum")}
+	for _, month := range months {
+		cells = append(cells, byMonth(month).String())
+	}
+	cells = append(cells, sum.String())
+	fmt.Fprintln(w, strings.Join(cells, "\t")+"\t")
+}
+
+// matrixName returns indented name of section, marked if it's not in sum.
+func matrixName(sect *Section) string {
+	name := strings.Repeat("  ", sect.Level()) + sect.Name()
+	if sect.SkipFromSum() {
+		name += " *" // not in sum
+	}
+	return name
+}
+
+// padNames returns function, which pads names to width of the longest of given
+// names. tabwriter with AlignRight aligns all columns to the right, so tables
+// align names by padding. Tabs in names are replaced by spaces.
+func padNames(names []string) func(string) string {
+	var width int
+	for _, s := range names {
+		width = max(width, utf8.RuneCountInString(s))
+	}
+	return func(s string) string {
+		s = strings.ReplaceAll(s, "\t", " ")
+		return s + strings.Repeat(" ", max(width-utf8.RuneCountInString(s), 0))
+	}
+}
@@ -0,0 +1,271 @@
+package app
+
+import (
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+const (
+	// moneyDigits is number of decimal digits of minor units.
+	moneyDigits = 2
+	moneyScale  = 100
+)
+
+// Money is an exact amount of money, kept in integer minor units (hundredths)
+// of its currency.
+type Money struct {
+	amount   int64
+	currency string
+}
+
+func NewMoney(amount int64, currency string) Money {
+	return Money{amount: amount, currency: currency}
+}
+
+// ParseMoney parses amount like "-1 234,50", using given decimal separator.
+func ParseMoney(s, decimalSep, currency string) (Money, error) {
+	m := Money{currency: currency}
+	str := strings.Map(func(r rune) rune {
+		if r == ' ' || r == '\u00a0' {
+			return -1
+		}
+		return r
+	}, s)
+	if decimalSep != "" && decimalSep != "." {
+		str = strings.ReplaceAll(str, decimalSep, ".")
+	}
+
+	neg := strings.HasPrefix(str, "-")
+	if neg || strings.HasPrefix(str, "+") {
+		str = str[1:]
+	}
+	if strings.ContainsAny(str, "+-") {
+		return m, fmt.Errorf("parse money %q: unexpected sign", s)
+	}
+	intPart, fracPart, _ := strings.Cut(str, ".")
+	if intPart == "" && fracPart == "" {
+		return m, fmt.Errorf("parse money %q: empty amount", s)
+	}
+
+	if trimmed := strings.TrimRight(fracPart, "0"); len(trimmed) > moneyDigits {
+		return m, fmt.Errorf("parse money %q: more than %d decimal digits", s,
+			moneyDigits)
+	} else if len(fracPart) > moneyDigits {
+		fracPart = fracPart[:moneyDigits]
+	}
+	fracPart += strings.Repeat("0", moneyDigits-len(fracPart))
+
+	if intPart == "" {
+		intPart = "0"
+	}
+	amount, err := strconv.ParseInt(intPart+fracPart, 10, 64)
+	if err != nil {
+		return m, fmt.Errorf("parse money %q: %w", s, err)
+	}
+
+	if neg {
+		amount = -amount
+	}
+	m.amount = amount
+	return m, nil
+}
+
+// Amount returns amount of money in minor units.
+func (self Money) Amount() int64 {
+	return self.amount
+}
+
+func (self Money) Currency() string {
+	return self.currency
+}
+
+func (self Money) IsZero() bool {
+	return self.amount == 0
+}
+
+func (self Money) Negative() bool {
+	return self.amount < 0
+}
+
+func (self Money) Abs() Money {
+	if self.amount < 0 {
+		return self.Neg()
+	}
+	return self
+}
+
+func (self Money) Neg() Money {
+	self.amount = -self.amount
+	return self
+}
+
+func (self Money) Add(m Money) Money {
+	if self.currency == "" {
+		self.currency = m.currency
+	}
+	self.amount += m.amount
+	return self
+}
+
+func (self Money) Sub(m Money) Money {
+	return self.Add(m.Neg())
+}
+
+// Cmp compares amounts of money and returns -1, 0 or +1.
+func (self Money) Cmp(m Money) int {
+	switch {
+	case self.amount < m.amount:
+		return -1
+	case self.amount > m.amount:
+		return 1
+	}
+	return 0
+}
+
+// Div returns money divided by n, rounded half away from zero.
+func (self Money) Div(n int) Money {
+	if n == 0 {
+		return self
+	}
+	self.amount = divRound(self.amount, int64(n))
+	return self
+}
+
+// Convert returns money multiplied by exchange rate, rounded half away from
+// zero, in given currency.
+func (self Money) Convert(rate *big.Rat, currency string) Money {
+	r := new(big.Rat).Mul(new(big.Rat).SetInt64(self.amount), rate)
+	num, denom := r.Num(), r.Denom()
+
+	half := new(big.Int).Rsh(denom, 1)
+	if num.Sign() < 0 {
+		num.Sub(num, half)
+	} else {
+		num.Add(num, half)
+	}
+	return Money{amount: num.Quo(num, denom).Int64(), currency: currency}
+}
+
+func divRound(a, b int64) int64 {
+	if b < 0 {
+		a, b = -a, -b
+	}
+	if a < 0 {
+		return -((-a + b/2) / b)
+	}
+	return (a + b/2) / b
+}
+
+// Float returns approximate value of money, for comparisons in templates.
+func (self Money) Float() float64 {
+	return float64(self.amount) / float64(moneyScale)
+}
+
+func (self Money) String() string {
+	return self.decimal(moneyDigits)
+}
+
+// decimal formats money with given number of decimal digits.
+func (self Money) decimal(prec int) string {
+	amount := self.amount
+	if amount < 0 {
+		amount = -amount
+	}
+
+	if prec < moneyDigits {
+		amount = divRound(amount, pow10(moneyDigits-prec))
+	}
+
+	sign := ""
+	if self.amount < 0 && amount != 0 {
+		sign = "-"
+	}
+
+	scale := pow10(min(prec, moneyDigits))
+	s := sign + strconv.FormatInt(amount/scale, 10)
+	if prec > 0 {
+		frac := strconv.FormatInt(amount%scale, 10)
+		s += "." + strings.Repeat("0", min(prec, moneyDigits)-len(frac)) + frac
+		if prec > moneyDigits {
+			s += strings.Repeat("0", prec-moneyDigits)
+		}
+	}
+	return s
+}
+
+// Format implements fmt.Formatter, so money can be printed exactly using
+// float verbs, like "%12.02f".
+func (self Money) Format(f fmt.State, verb rune) {
+	switch verb {
+	case 'f', 'F', 'g', 'G', 'e', 'E', 'v', 's':
+	default:
+		fmt.Fprintf(f, "%%!%c(app.Money=%s)", verb, self.String())
+		return
+	}
+
+	prec, ok := f.Precision()
+	if !ok {
+		prec = moneyDigits
+	}
+	s := self.decimal(prec)
+	if f.Flag('+') && !strings.HasPrefix(s, "-") {
+		s = "+" + s
+	}
+
+	if width, ok := f.Width(); ok && len(s) < width {
+		pad := width - len(s)
+		switch {
+		case f.Flag('-'):
+			s += strings.Repeat(" ", pad)
+		case f.Flag('0'):
+			sign := ""
+			if strings.HasPrefix(s, "-") || strings.HasPrefix(s, "+") {
+				sign, s = s[:1], s[1:]
+			}
+			s = sign + strings.Repeat("0", pad) + s
+		default:
+			s = strings.Repeat(" ", pad) + s
+		}
+	}
+	_, _ = f.Write([]byte(s))
+}
+
+// MarshalJSON encodes money as exact JSON number, like 1234.50.
+func (self Money) MarshalJSON() ([]byte, error) {
+	return []byte(self.String()), nil
+}
+
+// Grouped returns money with thousands separated by space and currency, like
+// "1 234.50 CZK".
+func (self Money) Grouped() string {
+	s := self.String()
+	sign := ""
+	if strings.HasPrefix(s, "-") {
+		sign, s = s[:1], s[1:]
+	}
+
+	intPart, fracPart, _ := strings.Cut(s, ".")
+	var b strings.Builder
+	b.WriteString(sign)
+	for i, c := range intPart {
+		if i > 0 && (len(intPart)-i)%3 == 0 {
+			b.WriteByte(' ')
+		}
+		b.WriteRune(c)
+	}
+	b.WriteString("." + fracPart)
+	if self.currency != "" {
+		b.WriteString(" " + self.currency)
+	}
+	return b.String()
+}
+
+func pow10(n int) int64 {
+	p := int64(1)
+	for range n {
+		p *= 10
+	}
+	return p
+}
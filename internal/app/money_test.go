@@ -0,0 +1,139 @@
+package app
+
+import (
+	"fmt"
+	"math/big"
+	"testing"
+)
+
+func TestParseMoney(t *testing.T) {
+	tests := []struct {
+		s          string
+		decimalSep string
+		want       int64
+		wantErr    bool
+	}{
+		{s: "-1 234,50", decimalSep: ",", want: -123450},
+		{s: "1 234,50", decimalSep: ",", want: 123450},
+		{s: "1234.5", decimalSep: ".", want: 123450},
+		{s: "1234.5", decimalSep: "", want: 123450},
+		{s: "0,01", decimalSep: ",", want: 1},
+		{s: "-0.10", decimalSep: ".", want: -10},
+		{s: ".5", decimalSep: ".", want: 50},
+		{s: "+12", decimalSep: ".", want: 1200},
+		{s: "1.230", decimalSep: ".", want: 123},
+		{s: "", decimalSep: ".", wantErr: true},
+		{s: "-", decimalSep: ".", wantErr: true},
+		{s: "1.234", decimalSep: ".", wantErr: true},
+		{s: "abc", decimalSep: ".", wantErr: true},
+		{s: "+-5", decimalSep: ".", wantErr: true},
+		{s: "--5", decimalSep: ".", wantErr: true},
+		{s: "-+5", decimalSep: ".", wantErr: true},
+		{s: "5-", decimalSep: ".", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		m, err := ParseMoney(tt.s, tt.decimalSep, "CZK")
+		switch {
+		case tt.wantErr && err == nil:
+			t.Errorf("ParseMoney(%q): expected error, got %d", tt.s, m.Amount())
+		case !tt.wantErr && err != nil:
+			t.Errorf("ParseMoney(%q): %v", tt.s, err)
+		case !tt.wantErr && m.Amount() != tt.want:
+			t.Errorf("ParseMoney(%q) = %d, want %d", tt.s, m.Amount(), tt.want)
+		case !tt.wantErr && m.Currency() != "CZK":
+			t.Errorf("ParseMoney(%q): currency %q, want CZK", tt.s, m.Currency())
+		}
+	}
+}
+
+func TestDivRound(t *testing.T) {
+	tests := []struct {
+		a, b int64
+		want int64
+	}{
+		{a: 4, b: 2, want: 2},
+		{a: 5, b: 2, want: 3},
+		{a: -5, b: 2, want: -3},
+		{a: 1, b: 3, want: 0},
+		{a: 2, b: 3, want: 1},
+		{a: -1, b: 3, want: 0},
+		{a: -2, b: 3, want: -1},
+		{a: 7, b: -2, want: -4},
+		{a: -3, b: -2, want: 2},
+		{a: 0, b: 7, want: 0},
+	}
+
+	for _, tt := range tests {
+		if got := divRound(tt.a, tt.b); got != tt.want {
+			t.Errorf("divRound(%d, %d) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestMoney_Convert(t *testing.T) {
+	tests := []struct {
+		amount int64
+		rate   string
+		want   int64
+	}{
+		{amount: 100, rate: "25.5", want: 2550},
+		{amount: 1234, rate: "1", want: 1234},
+		{amount: 1, rate: "1/2", want: 1},
+		{amount: -1, rate: "1/2", want: -1},
+		{amount: 5, rate: "1/2", want: 3},
+		{amount: -5, rate: "1/2", want: -3},
+		{amount: 1, rate: "1/3", want: 0},
+		{amount: -1, rate: "1/3", want: 0},
+		{amount: 2, rate: "1/3", want: 1},
+		{amount: 1999, rate: "0.04", want: 80},
+	}
+
+	for _, tt := range tests {
+		rate, ok := new(big.Rat).SetString(tt.rate)
+		if !ok {
+			t.Fatalf("invalid rate %q", tt.rate)
+		}
+		m := NewMoney(tt.amount, "EUR").Convert(rate, "CZK")
+		if m.Amount() != tt.want || m.Currency() != "CZK" {
+			t.Errorf("Convert(%d, %s) = %d %s, want %d CZK", tt.amount, tt.rate,
+				m.Amount(), m.Currency(), tt.want)
+		}
+	}
+}
+
+func TestMoney_Format(t *testing.T) {
+	tests := []struct {
+		format string
+		amount int64
+		want   string
+	}{
+		{format: "%v", amount: 123, want: "1.23"},
+		{format: "%v", amount: -5, want: "-0.05"},
+		{format: "%s", amount: 0, want: "0.00"},
+		{format: "%f", amount: 123, want: "1.23"},
+		{format: "%.02f", amount: 123450, want: "1234.50"},
+		{format: "%12.02f", amount: 123450, want: "     1234.50"},
+		{format: "%-8.2f|", amount: 100, want: "1.00    |"},
+		{format: "%08.2f", amount: -100, want: "-0001.00"},
+		{format: "%+.2f", amount: 100, want: "+1.00"},
+		{format: "%+.2f", amount: -100, want: "-1.00"},
+		{format: "%.0f", amount: 150, want: "2"},
+		{format: "%.0f", amount: 250, want: "3"},
+		{format: "%.0f", amount: -150, want: "-2"},
+		{format: "%.0f", amount: -50, want: "-1"},
+		{format: "%.0f", amount: -49, want: "0"},
+		{format: "%.1f", amount: 105, want: "1.1"},
+		{format: "%.1f", amount: -105, want: "-1.1"},
+		{format: "%.3f", amount: 105, want: "1.050"},
+		{format: "%d", amount: 123, want: "%!d(app.Money=1.23)"},
+	}
+
+	for _, tt := range tests {
+		got := fmt.Sprintf(tt.format, NewMoney(tt.amount, "CZK"))
+		if got != tt.want {
+			t.Errorf("Sprintf(%q, %d) = %q, want %q", tt.format, tt.amount, got,
+				tt.want)
+		}
+	}
+}
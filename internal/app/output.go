@@ -0,0 +1,140 @@
+package app
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+)
+
+// Output formats of a report.
+const (
+	OutputText   = "text"
+	OutputJSON   = "json"
+	OutputCSV    = "csv"
+	OutputXLSX   = "xlsx"
+	OutputHTML   = "html"
+	OutputMatrix = "matrix"
+)
+
+var Outputs = []string{
+	OutputText, OutputJSON, OutputCSV, OutputXLSX, OutputHTML, OutputMatrix,
+}
+
+type reportJSON struct {
+	Period struct {
+		Begin  string `json:"begin"`
+		End    string `json:"end"`
+		Months int    `json:"months"`
+	} `json:"period"`
+	Currency string      `json:"currency,omitempty"`
+	Expenses totalJSON   `json:"expenses"`
+	Income   totalJSON   `json:"income"`
+	Net      Money       `json:"net"`
+	Sections []*sectJSON `json:"sections"`
+	// IncomeSections has the same structure as Sections.
+	IncomeSections []*sectJSON  `json:"incomeSections"`
+	Budgets        []budgetJSON `json:"budgets,omitempty"`
+	Tags           []*itemJSON  `json:"tags,omitempty"`
+	IncomeTags     []*itemJSON  `json:"incomeTags,omitempty"`
+}
+
+type totalJSON struct {
+	Money    Money  `json:"money"`
+	Count    int    `json:"count"`
+	PerMonth *Money `json:"perMonth"`
+}
+
+type sectJSON struct {
+	Name        string `json:"name"`
+	SkipFromSum bool   `json:"skipFromSum"`
+	totalJSON
+	Items    []*itemJSON `json:"items"`
+	Sections []*sectJSON `json:"sections,omitempty"`
+}
+
+type budgetJSON struct {
+	Name        string  `json:"name"`
+	Budget      Money   `json:"budget"`
+	Spent       Money   `json:"spent"`
+	Remaining   Money   `json:"remaining"`
+	PercentUsed float64 `json:"percentUsed"`
+	Over        bool    `json:"over"`
+}
+
+type itemJSON struct {
+	Name string `json:"name"`
+	totalJSON
+}
+
+func newTotalJSON(money Money, count int, perMonth Money, ok bool) totalJSON {
+	t := totalJSON{Money: money, Count: count}
+	if ok {
+		t.PerMonth = &perMonth
+	}
+	return t
+}
+
+func newSectionsJSON(sections []*Section) []*sectJSON {
+	sectsJSON := make([]*sectJSON, len(sections))
+	for i, sect := range sections {
+		perMonth, ok := sect.perMonth()
+		sectsJSON[i] = &sectJSON{
+			Name:        sect.Name(),
+			SkipFromSum: sect.SkipFromSum(),
+			totalJSON:   newTotalJSON(sect.Money(), sect.Count(), perMonth, ok),
+			Items:       newItemsJSON(sect.SortedItems()),
+			Sections:    newSectionsJSON(sect.SortedSections()),
+		}
+	}
+	return sectsJSON
+}
+
+func newItemsJSON(items []*SectionItem) []*itemJSON {
+	itemsJSON := make([]*itemJSON, len(items))
+	for i, item := range items {
+		perMonth, ok := item.perMonth()
+		itemsJSON[i] = &itemJSON{
+			Name:      item.Name(),
+			totalJSON: newTotalJSON(item.Money(), item.Count(), perMonth, ok),
+		}
+	}
+	return itemsJSON
+}
+
+func (self *ReportData) WriteJSON(w io.Writer) error {
+	r := &reportJSON{
+		Currency:       self.Currency(),
+		Net:            self.Net(),
+		Sections:       newSectionsJSON(self.SortedSections()),
+		IncomeSections: newSectionsJSON(self.SortedIncomeSections()),
+		Tags:           newItemsJSON(self.SortedTags()),
+		IncomeTags:     newItemsJSON(self.SortedIncomeTags()),
+	}
+	r.Period.Begin = self.BeginDateString()
+	r.Period.End = self.EndDateString()
+	r.Period.Months = self.MonthsBetween()
+
+	for _, b := range self.Budgets() {
+		r.Budgets = append(r.Budgets, budgetJSON{
+			Name:        b.Name(),
+			Budget:      b.Budget(),
+			Spent:       b.Spent(),
+			Remaining:   b.Remaining(),
+			PercentUsed: math.Round(b.PercentUsed()*10) / 10,
+			Over:        b.Over(),
+		})
+	}
+
+	perMonth, ok := self.perMonth()
+	r.Expenses = newTotalJSON(self.Money(), self.Count(), perMonth, ok)
+	perMonth, ok = self.incomePerMonth()
+	r.Income = newTotalJSON(self.Income(), self.IncomeCount(), perMonth, ok)
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(r); err != nil {
+		return fmt.Errorf("encode json report: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,180 @@
+package app
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// loadOverridesConfig loads config with overrides file.
+func loadOverridesConfig(t *testing.T, cfg, overrides string) (*Config, error) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "overrides.yaml")
+	if err := os.WriteFile(path, []byte(overrides), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return loadTestConfig(t, cfg+"\noverrides: "+path+"\n")
+}
+
+const testOverridesConfig = `
+sections:
+  - name: Food
+    rules:
+      - re: '^Nákup: (ALBERT),'
+        tags: [groceries]
+  - name: Gifts
+    rules:
+      - re: '^Flowers'
+        key: Flowers
+  - name: Salary
+    income: true
+`
+
+func TestOverride(t *testing.T) {
+	cfg, err := loadOverridesConfig(t, testOverridesConfig, `
+overrides:
+  - id: "100"
+    section: Gifts
+    key: Birthday cake
+  - date: 2026-04-06
+    amount: "-30,00"
+    account: 123456/0100
+    note: Flowers for mom
+  - id: "102"
+    exclude: true
+  - id: "103"
+    section: Gifts
+  - date: 2026-04-08
+    amount: "-50,00"
+    section: Gifts
+    key: Dinner
+  - id: "999"
+    section: Gifts
+  - date: 2026-05-01
+    amount: "-1,00"
+    exclude: true
+  - id: "104"
+    section: Salary
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rec := func(line int, id, date string, amount int64, account, note string,
+	) Record {
+		r := testRecord(t, line, date, amount, note)
+		r.id, r.accountId = id, account
+		return r
+	}
+	records := []Record{
+		rec(1, "100", "2026-04-05", -50000, "", "Nákup: BILLA, Praha, CZ"),
+		rec(2, "101", "2026-04-06", -3000, "123456/0100", "Payment"),
+		rec(3, "102", "2026-04-06", -3000, "", "Nákup: ALBERT, Praha, CZ"),
+		rec(4, "103", "2026-04-07", -25010, "", "Nákup: ALBERT, Praha, CZ"),
+		rec(5, "", "2026-04-08", -5000, "", "Restaurant"),
+		rec(6, "105", "2026-04-09", -3000, "999999/0100", "Payment"),
+	}
+
+	tests := []struct {
+		line    int
+		section string
+		key     string
+		tags    []string
+	}{
+		{line: 1, section: "Gifts", key: "Birthday cake"},
+		{line: 2, section: "Gifts", key: "Flowers"},
+		{line: 4, section: "Gifts", key: "ALBERT", tags: []string{"groceries"}},
+		{line: 5, section: "Gifts", key: "Dinner"},
+		{line: 6},
+	}
+
+	report := NewReport(cfg)
+	var matched []Match
+	var lines []int
+	err = report.Each(NewRecords(records), func(r Record) error {
+		m, err := cfg.MatchSection(r)
+		matched = append(matched, m)
+		lines = append(lines, r.Line())
+		return err
+	})
+	if err != nil {
+		t.Fatalf("Each: %v", err)
+	}
+
+	if want := []int{1, 2, 4, 5, 6}; !slices.Equal(lines, want) {
+		t.Fatalf("lines = %v, want %v", lines, want)
+	}
+	for i, tt := range tests {
+		m := matched[i]
+		if m.Section != tt.section || m.Key != tt.key ||
+			!slices.Equal(m.Tags, tt.tags) {
+			t.Errorf("line %d: matched %q %q %v, want %q %q %v", tt.line,
+				m.Section, m.Key, m.Tags, tt.section, tt.key, tt.tags)
+		}
+	}
+
+	var unused []string
+	for _, o := range report.UnusedOverrides() {
+		unused = append(unused, o.String())
+	}
+	want := []string{"override 5 (id 999)", "override 7 (id 104)"}
+	if !slices.Equal(unused, want) {
+		t.Errorf("UnusedOverrides() = %q, want %q", unused, want)
+	}
+}
+
+func TestOverride_pinDirection(t *testing.T) {
+	cfg, err := loadOverridesConfig(t, testOverridesConfig, `
+overrides:
+  - id: "100"
+    section: Salary
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r := testRecord(t, 1, "2026-04-05", -50000, "Nákup: ALBERT, Praha, CZ")
+	r.id = "100"
+	err = NewReport(cfg).Each(NewRecords([]Record{r}), func(r Record) error {
+		_, err := cfg.MatchSection(r)
+		return err
+	})
+	if err == nil || !strings.Contains(err.Error(), "different direction") {
+		t.Errorf("pin to income section: error %v, want different direction", err)
+	}
+}
+
+func TestOverride_compile(t *testing.T) {
+	tests := []struct {
+		name      string
+		overrides string
+		wantErr   string
+	}{
+		{
+			name:      "no id or date",
+			overrides: "overrides:\n  - amount: \"-1,00\"\n    exclude: true\n",
+			wantErr:   "either id or date and amount required",
+		},
+		{
+			name:      "unknown section",
+			overrides: "overrides:\n  - id: \"1\"\n    section: Unknown\n",
+			wantErr:   `unknown section "Unknown"`,
+		},
+		{
+			name:      "split without section",
+			overrides: "overrides:\n  - id: \"1\"\n    split:\n      - weight: 1\n",
+			wantErr:   "split 0: section required",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := loadOverridesConfig(t, testOverridesConfig, tt.overrides)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("LoadConfig: error %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,260 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Override pins one payment, found by its transaction ID or by date, amount and
+// optionally account, to a section and key, excludes it from reports or renames
+// its note. It's applied before matching rules of sections.
+type Override struct {
+	Id      string
+	Date    string
+	Amount  string
+	Account string
+
+	// Section and Key pin the payment. Key defaults to key of matched rule or
+	// to note of the payment.
+	Section string
+	Key     string
+	// Note replaces note of the payment before matching rules.
+	Note    string
+	Exclude bool
+	Split   []*SplitPart
+
+	idx    int
+	date   time.Time
+	amount Money
+	// sect is pinned section, nil for synthetic uncategorized section.
+	sect *SectionConfig
+}
+
+func (self *Override) compile(cfg *Config) error {
+	if self.Date != "" {
+		d, err := time.Parse("2006-01-02", self.Date)
+		if err != nil {
+			return fmt.Errorf("parse date: %w", err)
+		}
+		self.date = d
+	}
+
+	if self.Amount != "" {
+		m, err := parseConfigMoney(self.Amount, "")
+		if err != nil {
+			return err
+		}
+		self.amount = m
+	}
+
+	if self.Id == "" && (self.Date == "" || self.Amount == "") {
+		return errors.New("either id or date and amount required")
+	}
+	return self.compileSection(cfg)
+}
+
+func (self *Override) compileSection(cfg *Config) error {
+	var sect *SectionConfig
+	if self.Section != "" {
+		s, ok := cfg.sectionIndex[self.Section]
+		if !ok {
+			return fmt.Errorf("unknown section %q", self.Section)
+		}
+		sect = s
+		if !cfg.synthetic(s) {
+			self.sect = s
+		}
+	}
+
+	for i, part := range self.Split {
+		if sect == nil && part.Section == "" {
+			return fmt.Errorf("split %d: section required", i)
+		}
+		s := sect
+		if s == nil {
+			s = cfg.sectionIndex[part.Section]
+		}
+		if err := part.compile(cfg, s); err != nil {
+			return fmt.Errorf("split %d: %w", i, err)
+		}
+	}
+	return nil
+}
+
+// String returns description of the override, like "override 2 (id 1234)".
+func (self *Override) String() string {
+	var fields []string
+	if self.Id != "" {
+		fields = append(fields, "id "+self.Id)
+	}
+	if self.Date != "" {
+		fields = append(fields, self.Date, self.Amount)
+	}
+	if self.Account != "" {
+		fields = append(fields, self.Account)
+	}
+	return fmt.Sprintf("override %d (%s)", self.idx, strings.Join(fields, " "))
+}
+
+func (self *Override) matchRecord(rec Record) bool {
+	if self.Id != "" && self.Id != rec.Id() {
+		return false
+	} else if self.Date == "" {
+		return true
+	}
+	return self.date.Equal(rec.Date()) &&
+		self.amount.Amount() == rec.Amount().Amount() &&
+		(self.Account == "" || self.Account == rec.AccountId())
+}
+
+// apply returns record with renamed note, which keeps this override for
+// MatchSection.
+func (self *Override) apply(rec Record) Record {
+	if self.Note != "" {
+		rec.note = self.Note
+	}
+	rec.override = self
+	return rec
+}
+
+// match returns pinned section and key instead of matched rule. Tags of
+// matched rule are kept.
+func (self *Override) match(m Match, rec Record) (Match, error) {
+	if self.Section != "" {
+		if self.sect != nil && self.sect.Income == rec.Out() {
+			return m, fmt.Errorf("%s: section %q has different direction", self,
+				self.Section)
+		}
+
+		key := m.Key
+		if !m.Found() {
+			key = rec.Note()
+			if key == "" {
+				key = rec.AccountId()
+			}
+		}
+		m = Match{Section: self.Section, Key: key, Tags: m.Tags}
+	}
+
+	if self.Key != "" && m.Section != "" {
+		m.Key = self.Key
+	}
+	if len(self.Split) > 0 {
+		m.split = self.Split
+	}
+	m.Override = self
+	return m, nil
+}
+
+// --------------------------------------------------
+
+type overridesFile struct {
+	Overrides []*Override
+}
+
+func (self *Config) compileOverrides() error {
+	if self.OverridesFile == "" {
+		return nil
+	}
+
+	path, err := expandHomeDir(self.OverridesFile)
+	if err != nil {
+		return fmt.Errorf("expand home dir in %q: %w", self.OverridesFile, err)
+	}
+
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("read overrides: %w", err)
+	}
+
+	var file overridesFile
+	if err := yaml.Unmarshal(b, &file); err != nil {
+		return fmt.Errorf("yaml decode %q: %w", path, err)
+	}
+
+	self.overrides = file.Overrides
+	self.overridesById = make(map[string][]*Override)
+	for i, o := range self.overrides {
+		o.idx = i
+		if err := o.compile(self); err != nil {
+			return fmt.Errorf("overrides %q: override %d: %w", path, i, err)
+		}
+		self.overridesById[o.Id] = append(self.overridesById[o.Id], o)
+	}
+	return nil
+}
+
+// FindOverride returns override of given record or nil.
+func (self *Config) FindOverride(rec Record) *Override {
+	if len(self.overrides) == 0 {
+		return nil
+	}
+
+	if rec.Id() != "" {
+		for _, o := range self.overridesById[rec.Id()] {
+			if o.matchRecord(rec) {
+				return o
+			}
+		}
+	}
+
+	for _, o := range self.overridesById[""] {
+		if o.matchRecord(rec) {
+			return o
+		}
+	}
+	return nil
+}
+
+// Overrides returns all loaded overrides.
+func (self *Config) Overrides() []*Override {
+	return self.overrides
+}
+
+// --------------------------------------------------
+
+// overridesUsage remembers overrides, which matched any record, and dates of
+// all records.
+type overridesUsage struct {
+	used      map[*Override]struct{}
+	firstDate time.Time
+	lastDate  time.Time
+}
+
+func newOverridesUsage() *overridesUsage {
+	return &overridesUsage{used: make(map[*Override]struct{})}
+}
+
+func (self *overridesUsage) seen(rec Record, o *Override) {
+	if o != nil {
+		self.used[o] = struct{}{}
+	}
+
+	if self.firstDate.IsZero() || rec.Date().Before(self.firstDate) {
+		self.firstDate = rec.Date()
+	}
+	if rec.Date().After(self.lastDate) {
+		self.lastDate = rec.Date()
+	}
+}
+
+// unused returns overrides, which didn't match any record. Overrides with date
+// outside of dates of all records are ignored.
+func (self *overridesUsage) unused(overrides []*Override) []*Override {
+	var unused []*Override
+	for _, o := range overrides {
+		if _, ok := self.used[o]; ok {
+			continue
+		} else if o.Date != "" &&
+			(o.date.Before(self.firstDate) || o.date.After(self.lastDate)) {
+			continue
+		}
+		unused = append(unused, o)
+	}
+	return unused
+}
@@ -3,11 +3,10 @@ package app
 import (
 	"bufio"
 	"encoding/csv"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
-	"math"
-	"strconv"
 	"strings"
 	"time"
 )
@@ -24,7 +23,7 @@ func skipBOM(r io.Reader) (*bufio.Reader, error) {
 	return br, nil
 }
 
-func NewParser(file io.Reader) (*Parser, error) {
+func NewParser(file io.Reader, format *CSVConfig) (*Parser, error) {
 	// skip BOM
 	br, err := skipBOM(file)
 	if err != nil {
@@ -32,10 +31,10 @@ func NewParser(file io.Reader) (*Parser, error) {
 	}
 
 	csvReader := csv.NewReader(br)
-	csvReader.Comma = ';'
+	csvReader.Comma = format.comma
 	csvReader.ReuseRecord = true
 
-	p := &Parser{csvReader: csvReader}
+	p := &Parser{csvReader: csvReader, format: format}
 	if err := p.parseHeader(); err != nil {
 		return nil, err
 	}
@@ -45,7 +44,10 @@ func NewParser(file io.Reader) (*Parser, error) {
 
 type Parser struct {
 	csvReader *csv.Reader
-	headers   []string
+	format    *CSVConfig
+	// headers contains logical field for every column or empty string for
+	// unknown columns.
+	headers []string
 }
 
 func (self *Parser) parseHeader() error {
@@ -53,8 +55,12 @@ func (self *Parser) parseHeader() error {
 	if err != nil {
 		return fmt.Errorf("csv header: %w", err)
 	}
+
+	fields := self.format.fieldsByColumn()
 	self.headers = make([]string, 0, len(r))
-	self.headers = append(self.headers, r...)
+	for _, col := range r {
+		self.headers = append(self.headers, fields[col])
+	}
 	return nil
 }
 
@@ -69,10 +75,11 @@ func (self *Parser) Next() (rec Record, err error) {
 
 	fields := map[string]string{}
 	for i, s := range r {
-		name := self.headers[i]
-		fields[name] = s
+		if name := self.headers[i]; name != "" {
+			fields[name] = s
+		}
 	}
-	err = rec.Parse(fields, line)
+	err = rec.Parse(fields, line, self.format)
 
 	return
 }
@@ -80,77 +87,93 @@ func (self *Parser) Next() (rec Record, err error) {
 // --------------------------------------------------
 
 type Record struct {
+	id        string
 	date      time.Time
 	accountId string
 	note      string
 	vs        string
+	ks        string
+	ss        string
+	typ       string
 
 	line  int
-	money float64
+	money Money
 	valid bool
+
+	// override of this record, applied by Report.Each
+	override *Override
 }
 
 func (self *Record) Valid() bool {
 	return self.valid
 }
 
-func (self *Record) Parse(fields map[string]string, line int) error {
+// Parse fills the record from fields, keyed by logical names, like date or
+// amount.
+func (self *Record) Parse(fields map[string]string, line int, format *CSVConfig,
+) error {
 	self.line = line
 
-	if err := self.parseDate(fields); err != nil {
+	if err := self.parseDate(fields, format.DateLayout); err != nil {
 		return err
 	}
 
-	if err := self.parseMoney(fields); err != nil {
+	if err := self.parseMoney(fields, format.Decimal); err != nil {
 		return err
 	}
 
 	self.valid = true
+	self.id = fields[fieldId]
 	self.parseAccount(fields)
 	self.parseNote(fields)
-	self.vs = fields["VS"]
+	self.vs = fields[fieldVs]
+	self.ks = fields[fieldKs]
+	self.ss = fields[fieldSs]
+	self.typ = fields[fieldType]
 
 	return nil
 }
 
-func (self *Record) parseDate(fields map[string]string) error {
-	if date, err := time.Parse("02.01.2006", fields["Datum"]); err != nil {
+func (self *Record) parseDate(fields map[string]string, layout string) error {
+	if date, err := time.Parse(layout, fields[fieldDate]); err != nil {
 		return fmt.Errorf("parse date, line %d: %w", self.line, err)
 	} else {
-		self.date = date
+		// drop time zone of dates from Fio API, like 2012-07-27+0200
+		y, m, d := date.Date()
+		self.date = time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
 	}
 	return nil
 }
 
-func (self *Record) parseMoney(fields map[string]string) error {
-	moneyStr := fields["Objem"]
-	moneyStr = strings.ReplaceAll(moneyStr, ",", ".")
-	money, err := strconv.ParseFloat(moneyStr, 32)
+func (self *Record) parseMoney(fields map[string]string, decimalSep string,
+) error {
+	currency := strings.ToUpper(strings.TrimSpace(fields[fieldCurrency]))
+	money, err := ParseMoney(fields[fieldAmount], decimalSep, currency)
 	if err != nil {
-		return fmt.Errorf("parse float %q: %w", moneyStr, err)
+		return fmt.Errorf("line %d: %w", self.line, err)
 	}
 	self.money = money
 	return nil
 }
 
 func (self *Record) parseAccount(fields map[string]string) {
-	if fields["Protiúčet"] != "" && fields["Kód banky"] != "" {
-		self.accountId = fields["Protiúčet"] + "/" + fields["Kód banky"]
+	if fields[fieldAccount] != "" && fields[fieldBankCode] != "" {
+		self.accountId = fields[fieldAccount] + "/" + fields[fieldBankCode]
 	} else {
-		self.accountId = fields["Protiúčet"]
+		self.accountId = fields[fieldAccount]
 	}
 }
 
 func (self *Record) parseNote(fields map[string]string) {
 	switch {
-	case self.accountId != "" && fields["Poznámka"] != "":
-		self.note = fields["Poznámka"]
-	case fields["Zpráva pro příjemce"] == "" && fields["Poznámka"] != "":
-		self.note = fields["Poznámka"]
+	case self.accountId != "" && fields[fieldNote] != "":
+		self.note = fields[fieldNote]
+	case fields[fieldMessage] == "" && fields[fieldNote] != "":
+		self.note = fields[fieldNote]
 	default:
 		fallback := []string{
-			self.accountId, fields["Zpráva pro příjemce"], fields["Poznámka"],
-			fields["Typ"],
+			self.accountId, fields[fieldMessage], fields[fieldNote],
+			fields[fieldType],
 		}
 		for _, v := range fallback {
 			if v != "" {
@@ -161,16 +184,32 @@ func (self *Record) parseNote(fields map[string]string) {
 	}
 }
 
+// Id returns transaction ID, like "ID pohybu" from Fio banka, or empty string.
+func (self *Record) Id() string {
+	return self.id
+}
+
 func (self *Record) Line() int {
 	return self.line
 }
 
 func (self *Record) Out() bool {
-	return self.money < 0
+	return self.money.Negative()
+}
+
+// Money returns absolute amount of the payment.
+func (self *Record) Money() Money {
+	return self.money.Abs()
+}
+
+// Amount returns amount of the payment, which is negative for outgoing
+// payments.
+func (self *Record) Amount() Money {
+	return self.money
 }
 
-func (self *Record) Money() float32 {
-	return float32(math.Abs(self.money))
+func (self *Record) Currency() string {
+	return self.money.Currency()
 }
 
 func (self *Record) Date() time.Time {
@@ -189,6 +228,88 @@ func (self *Record) Vs() string {
 	return self.vs
 }
 
+func (self *Record) Ks() string {
+	return self.ks
+}
+
+func (self *Record) Ss() string {
+	return self.ss
+}
+
+func (self *Record) Type() string {
+	return self.typ
+}
+
+// String returns short description of the record: its line, date, amount and
+// note.
+func (self *Record) String() string {
+	return fmt.Sprintf("line %d: %s %.02f %q", self.line,
+		self.date.Format("2006-01-02"), self.money, self.note)
+}
+
+type recordJSON struct {
+	Id       string `json:"id,omitempty"`
+	Date     string `json:"date"`
+	Amount   string `json:"amount"`
+	Currency string `json:"currency,omitempty"`
+	Account  string `json:"account,omitempty"`
+	Note     string `json:"note,omitempty"`
+	Vs       string `json:"vs,omitempty"`
+	Ks       string `json:"ks,omitempty"`
+	Ss       string `json:"ss,omitempty"`
+	Type     string `json:"type,omitempty"`
+}
+
+func (self *Record) MarshalJSON() ([]byte, error) {
+	b, err := json.Marshal(&recordJSON{
+		Id:       self.id,
+		Date:     self.date.Format("2006-01-02"),
+		Amount:   self.money.String(),
+		Currency: self.money.Currency(),
+		Account:  self.accountId,
+		Note:     self.note,
+		Vs:       self.vs,
+		Ks:       self.ks,
+		Ss:       self.ss,
+		Type:     self.typ,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("marshal record: %w", err)
+	}
+	return b, nil
+}
+
+func (self *Record) UnmarshalJSON(b []byte) error {
+	var r recordJSON
+	if err := json.Unmarshal(b, &r); err != nil {
+		return fmt.Errorf("unmarshal record: %w", err)
+	}
+
+	date, err := time.Parse("2006-01-02", r.Date)
+	if err != nil {
+		return fmt.Errorf("parse date: %w", err)
+	}
+
+	money, err := ParseMoney(r.Amount, ".", r.Currency)
+	if err != nil {
+		return err
+	}
+
+	*self = Record{
+		id:        r.Id,
+		date:      date,
+		accountId: r.Account,
+		note:      r.Note,
+		vs:        r.Vs,
+		ks:        r.Ks,
+		ss:        r.Ss,
+		typ:       r.Type,
+		money:     money,
+		valid:     true,
+	}
+	return nil
+}
+
 func (self *Record) Between(d1 time.Time, d2 time.Time) bool {
 	if self.Date().Before(d1) {
 		return false
@@ -0,0 +1,119 @@
+package app
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+func NewRates() *Rates {
+	return &Rates{
+		fixed: make(map[string]*big.Rat),
+		daily: make(map[string][]dailyRate),
+	}
+}
+
+// Rates keeps exchange rates of currencies into reporting currency. Daily rates
+// have priority over fixed ones.
+type Rates struct {
+	fixed map[string]*big.Rat
+	daily map[string][]dailyRate
+}
+
+type dailyRate struct {
+	date time.Time
+	rate *big.Rat
+}
+
+func parseRate(s string) (*big.Rat, error) {
+	s = strings.ReplaceAll(strings.TrimSpace(s), ",", ".")
+	rate, ok := new(big.Rat).SetString(s)
+	if !ok || rate.Sign() <= 0 {
+		return nil, fmt.Errorf("invalid exchange rate %q", s)
+	}
+	return rate, nil
+}
+
+func (self *Rates) SetFixed(currency, rate string) error {
+	r, err := parseRate(rate)
+	if err != nil {
+		return fmt.Errorf("currency %q: %w", currency, err)
+	}
+	self.fixed[strings.ToUpper(currency)] = r
+	return nil
+}
+
+func (self *Rates) LoadFile(path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("open rates %q: %w", path, err)
+	}
+	defer file.Close()
+
+	if err := self.Load(file); err != nil {
+		return fmt.Errorf("rates %q: %w", path, err)
+	}
+	return nil
+}
+
+// Load reads daily rates from CSV with lines like "2026-03-02;EUR;25,105".
+// Optional header line and lines starting with # are skipped.
+func (self *Rates) Load(r io.Reader) error {
+	csvReader := csv.NewReader(r)
+	csvReader.Comma = ';'
+	csvReader.Comment = '#'
+	csvReader.FieldsPerRecord = 3
+	csvReader.ReuseRecord = true
+
+	for i := 0; ; i++ {
+		row, err := csvReader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return fmt.Errorf("read csv: %w", err)
+		}
+
+		date, err := time.Parse("2006-01-02", strings.TrimSpace(row[0]))
+		if err != nil {
+			if i == 0 {
+				continue // header
+			}
+			return fmt.Errorf("parse date: %w", err)
+		}
+
+		rate, err := parseRate(row[2])
+		if err != nil {
+			return fmt.Errorf("date %s: %w", row[0], err)
+		}
+		currency := strings.ToUpper(strings.TrimSpace(row[1]))
+		self.daily[currency] = append(self.daily[currency], dailyRate{date, rate})
+	}
+
+	for _, rates := range self.daily {
+		sort.SliceStable(rates, func(i, j int) bool {
+			return rates[i].date.Before(rates[j].date)
+		})
+	}
+	return nil
+}
+
+// Rate returns latest daily rate of currency on or before given date, or its
+// fixed rate.
+func (self *Rates) Rate(currency string, date time.Time) (*big.Rat, bool) {
+	rates := self.daily[currency]
+	i := sort.Search(len(rates), func(i int) bool {
+		return rates[i].date.After(date)
+	})
+	if i > 0 {
+		return rates[i-1].rate, true
+	}
+
+	rate, ok := self.fixed[currency]
+	return rate, ok
+}
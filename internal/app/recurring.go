@@ -0,0 +1,272 @@
+package app
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// Cadence is a period of recurring payments.
+type Cadence struct {
+	Name   string
+	months int
+	// intervals between payments in days
+	minDays int
+	maxDays int
+	// graceDays is a delay of expected payment, before it's reported missing
+	graceDays int
+	// minCount is a minimal number of payments for detection
+	minCount int
+}
+
+var cadences = []*Cadence{
+	{
+		Name: "monthly", months: 1,
+		minDays: 25, maxDays: 35, graceDays: 7, minCount: 3,
+	},
+	{
+		Name: "quarterly", months: 3,
+		minDays: 80, maxDays: 100, graceDays: 14, minCount: 3,
+	},
+	{
+		Name: "yearly", months: 12,
+		minDays: 350, maxDays: 380, graceDays: 30, minCount: 2,
+	},
+}
+
+func (self *Cadence) regular(days int) bool {
+	return days >= self.minDays && days <= self.maxDays
+}
+
+// next returns expected date of the next payment after t. Like Jan 31 is
+// followed by Feb 28 or 29, not by Mar 3.
+func (self *Cadence) next(t time.Time) time.Time {
+	y, m, d := t.Date()
+	lastDay := time.Date(y, m+time.Month(self.months)+1, 0, 0, 0, 0, 0,
+		t.Location()).Day()
+	return time.Date(y, m+time.Month(self.months), min(d, lastDay), 0, 0, 0, 0,
+		t.Location())
+}
+
+// --------------------------------------------------
+
+func NewRecurring(cfg *Config) *Recurring {
+	return &Recurring{
+		cfg:    cfg,
+		groups: make(map[string]*recurringGroup),
+	}
+}
+
+// Recurring detects periodic payments, like subscriptions, in payments grouped
+// by account and VS, or by section and key of matched rule.
+type Recurring struct {
+	cfg    *Config
+	groups map[string]*recurringGroup
+}
+
+type recurringGroup struct {
+	name    string
+	section string
+	records []Record
+}
+
+// Add adds a payment into its group.
+func (self *Recurring) Add(rec Record) error {
+	m, err := self.cfg.MatchSection(rec)
+	if err != nil {
+		return err
+	}
+
+	var name string
+	switch {
+	case rec.AccountId() != "" && rec.Vs() != "":
+		name = rec.AccountId() + ", VS: " + rec.Vs()
+	case rec.AccountId() != "":
+		name = rec.AccountId()
+	case m.Found():
+		name = m.Section + ": " + m.Key
+	default:
+		name = rec.Note()
+	}
+
+	g := self.groups[name]
+	if g == nil {
+		g = &recurringGroup{name: name}
+		self.groups[name] = g
+	}
+	if m.Found() {
+		g.section = m.Section
+	}
+	g.records = append(g.records, rec)
+	return nil
+}
+
+// Detect returns recurring payments, biggest per month first. Expected
+// payments, which didn't come till asOf, are reported missing.
+func (self *Recurring) Detect(asOf time.Time) []*RecurringPayment {
+	var payments []*RecurringPayment
+	for _, g := range self.groups {
+		if p := g.detect(); p != nil {
+			p.updateStatus(asOf)
+			payments = append(payments, p)
+		}
+	}
+
+	sort.Slice(payments, func(i, j int) bool {
+		if c := payments[i].PerMonth().Cmp(payments[j].PerMonth()); c != 0 {
+			return c > 0
+		}
+		return payments[i].Name < payments[j].Name
+	})
+	return payments
+}
+
+func (self *recurringGroup) detect() *RecurringPayment {
+	records := self.records
+	if len(records) < 2 {
+		return nil
+	}
+	sort.SliceStable(records, func(i, j int) bool {
+		return records[i].Date().Before(records[j].Date())
+	})
+
+	intervals := make([]int, len(records)-1)
+	for i := 1; i < len(records); i++ {
+		intervals[i-1] = int(records[i].Date().Sub(records[i-1].Date()).Hours() / 24)
+	}
+	sorted := slices.Clone(intervals)
+	slices.Sort(sorted)
+	median := sorted[len(sorted)/2]
+
+	var cadence *Cadence
+	for _, c := range cadences {
+		if c.regular(median) && len(records) >= c.minCount {
+			cadence = c
+			break
+		}
+	}
+	if cadence == nil {
+		return nil
+	}
+
+	// most of intervals must be regular, others are missed payments
+	regular := 0
+	for _, days := range intervals {
+		if cadence.regular(days) {
+			regular++
+		}
+	}
+	if regular*2 < len(intervals) {
+		return nil
+	}
+
+	p := &RecurringPayment{
+		Name:    self.name,
+		Section: self.section,
+		Cadence: cadence,
+		Count:   len(records),
+		First:   records[0].Date(),
+	}
+	for _, rec := range records {
+		p.addPayment(rec)
+	}
+
+	// changes of amount are rare for recurring payments, unlike shopping
+	if len(p.PriceChanges)*2 > len(intervals) {
+		return nil
+	}
+	return p
+}
+
+// --------------------------------------------------
+
+// RecurringPayment is a detected periodic payment.
+type RecurringPayment struct {
+	Name    string
+	Section string
+	Cadence *Cadence
+	// Amount is the last amount of payment.
+	Amount Money
+	Count  int
+	First  time.Time
+	Last   time.Time
+	// Next is expected date of the next payment.
+	Next         time.Time
+	PriceChanges []PriceChange
+	// Missing is true, if expected payment didn't come in time, and Stopped is
+	// true, if more than one payment is missing.
+	Missing bool
+	Stopped bool
+}
+
+type PriceChange struct {
+	Date time.Time
+	From Money
+	To   Money
+}
+
+func (self *RecurringPayment) addPayment(rec Record) {
+	if !self.Last.IsZero() && rec.Money().Cmp(self.Amount) != 0 {
+		self.PriceChanges = append(self.PriceChanges, PriceChange{
+			Date: rec.Date(), From: self.Amount, To: rec.Money(),
+		})
+	}
+	self.Amount = rec.Money()
+	self.Last = rec.Date()
+	self.Next = self.Cadence.next(rec.Date())
+}
+
+func (self *RecurringPayment) updateStatus(asOf time.Time) {
+	grace := time.Duration(self.Cadence.graceDays) * 24 * time.Hour
+	self.Missing = asOf.After(self.Next.Add(grace))
+	self.Stopped = asOf.After(self.Cadence.next(self.Next).Add(grace))
+}
+
+// PerMonth returns amount of payment divided by months of its cadence.
+func (self *RecurringPayment) PerMonth() Money {
+	return self.Amount.Div(self.Cadence.months)
+}
+
+// --------------------------------------------------
+
+// WriteRecurring writes a table of recurring payments with their price changes
+// and total per month.
+func WriteRecurring(w io.Writer, payments []*RecurringPayment) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw,
+		"Payment\tSection\tCadence\tAmount\tCount\tLast\tNext\tStatus\tPrice changes")
+
+	var perMonth Money
+	for _, p := range payments {
+		status := ""
+		if p.Stopped {
+			status = "stopped?"
+		} else {
+			perMonth = perMonth.Add(p.PerMonth())
+			if p.Missing {
+				status = "missing!"
+			}
+		}
+
+		changes := make([]string, len(p.PriceChanges))
+		for i, c := range p.PriceChanges {
+			changes[i] = fmt.Sprintf("%.02f -> %.02f on %s", c.From, c.To,
+				c.Date.Format("2006-01-02"))
+		}
+
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%.02f\t%d\t%s\t%s\t%s\t%s\n",
+			strings.ReplaceAll(p.Name, "\t", " "), p.Section, p.Cadence.Name,
+			p.Amount, p.Count, p.Last.Format("2006-01-02"),
+			p.Next.Format("2006-01-02"), status, strings.Join(changes, ", "))
+	}
+
+	if err := tw.Flush(); err != nil {
+		return fmt.Errorf("write recurring: %w", err)
+	}
+	fmt.Fprintf(w, "\nActive recurring payments: %.02f per month\n", perMonth)
+	return nil
+}
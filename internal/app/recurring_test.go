@@ -0,0 +1,189 @@
+package app
+
+import (
+	"testing"
+	"time"
+)
+
+func testDate(t *testing.T, s string) time.Time {
+	t.Helper()
+	d, err := time.Parse("2006-01-02", s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return d
+}
+
+func TestCadence_next(t *testing.T) {
+	tests := []struct {
+		cadence string
+		date    string
+		want    string
+	}{
+		{cadence: "monthly", date: "2026-03-15", want: "2026-04-15"},
+		{cadence: "monthly", date: "2026-01-31", want: "2026-02-28"},
+		{cadence: "monthly", date: "2028-01-31", want: "2028-02-29"},
+		{cadence: "monthly", date: "2026-12-31", want: "2027-01-31"},
+		{cadence: "quarterly", date: "2026-11-30", want: "2027-02-28"},
+		{cadence: "yearly", date: "2028-02-29", want: "2029-02-28"},
+	}
+
+	for _, tt := range tests {
+		c := testCadence(t, tt.cadence)
+		got := c.next(testDate(t, tt.date)).Format("2006-01-02")
+		if got != tt.want {
+			t.Errorf("%s next(%s) = %s, want %s", tt.cadence, tt.date, got,
+				tt.want)
+		}
+	}
+}
+
+func testCadence(t *testing.T, name string) *Cadence {
+	t.Helper()
+	for _, c := range cadences {
+		if c.Name == name {
+			return c
+		}
+	}
+	t.Fatalf("unknown cadence %q", name)
+	return nil
+}
+
+type testPayment struct {
+	date   string
+	amount int64
+}
+
+func TestRecurringGroup_detect(t *testing.T) {
+	tests := []struct {
+		name     string
+		payments []testPayment
+		want     string // cadence or empty, if not detected
+		changes  int
+	}{
+		{
+			name: "monthly",
+			payments: []testPayment{
+				{"2026-01-05", 19900}, {"2026-02-05", 19900},
+				{"2026-03-05", 19900}, {"2026-04-05", 19900},
+			},
+			want: "monthly",
+		},
+		{
+			name: "monthly at end of month",
+			payments: []testPayment{
+				{"2026-01-31", 19900}, {"2026-02-28", 19900},
+				{"2026-03-31", 19900},
+			},
+			want: "monthly",
+		},
+		{
+			name: "two payments aren't enough for monthly",
+			payments: []testPayment{
+				{"2026-01-05", 19900}, {"2026-02-05", 19900},
+			},
+		},
+		{
+			name: "quarterly",
+			payments: []testPayment{
+				{"2025-10-01", 120000}, {"2026-01-01", 120000},
+				{"2026-04-01", 120000},
+			},
+			want: "quarterly",
+		},
+		{
+			name:     "yearly",
+			payments: []testPayment{{"2025-03-10", 99000}, {"2026-03-12", 99000}},
+			want:     "yearly",
+		},
+		{
+			name: "one missed payment",
+			payments: []testPayment{
+				{"2026-01-05", 19900}, {"2026-02-05", 19900},
+				{"2026-04-05", 19900}, {"2026-05-05", 19900},
+				{"2026-06-05", 19900},
+			},
+			want: "monthly",
+		},
+		{
+			name: "most payments missed",
+			payments: []testPayment{
+				{"2026-01-05", 19900}, {"2026-02-05", 19900},
+				{"2026-04-05", 19900}, {"2026-06-05", 19900},
+				{"2026-08-05", 19900},
+			},
+		},
+		{
+			name: "price change",
+			payments: []testPayment{
+				{"2026-01-05", 19900}, {"2026-02-05", 19900},
+				{"2026-03-05", 24900}, {"2026-04-05", 24900},
+			},
+			want:    "monthly",
+			changes: 1,
+		},
+		{
+			name: "shopping every week",
+			payments: []testPayment{
+				{"2026-03-02", 25010}, {"2026-03-09", 31250},
+				{"2026-03-16", 18700}, {"2026-03-23", 42000},
+			},
+		},
+		{
+			name: "shopping every month with different amounts",
+			payments: []testPayment{
+				{"2026-01-05", 25010}, {"2026-02-04", 31250},
+				{"2026-03-06", 18700}, {"2026-04-05", 42000},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := &recurringGroup{name: "test"}
+			for i, p := range tt.payments {
+				g.records = append(g.records, testRecord(t, i+1, p.date, -p.amount,
+					"Payment"))
+			}
+
+			p := g.detect()
+			switch {
+			case p == nil && tt.want != "":
+				t.Errorf("detect() = nil, want %s", tt.want)
+			case p != nil && tt.want == "":
+				t.Errorf("detect() = %s, want nil", p.Cadence.Name)
+			case p != nil && p.Cadence.Name != tt.want:
+				t.Errorf("detect() = %s, want %s", p.Cadence.Name, tt.want)
+			case p != nil && len(p.PriceChanges) != tt.changes:
+				t.Errorf("detect() price changes = %v, want %d", p.PriceChanges,
+					tt.changes)
+			}
+		})
+	}
+}
+
+func TestRecurringPayment_updateStatus(t *testing.T) {
+	monthly := testCadence(t, "monthly")
+	tests := []struct {
+		asOf    string
+		missing bool
+		stopped bool
+	}{
+		{asOf: "2026-04-05"},
+		{asOf: "2026-05-05"},
+		{asOf: "2026-05-12"}, // last day of grace
+		{asOf: "2026-05-13", missing: true},
+		{asOf: "2026-06-12", missing: true},
+		{asOf: "2026-06-13", missing: true, stopped: true},
+	}
+
+	for _, tt := range tests {
+		p := &RecurringPayment{Cadence: monthly}
+		p.addPayment(testRecord(t, 1, "2026-04-05", -19900, "Payment"))
+		p.updateStatus(testDate(t, tt.asOf))
+		if p.Missing != tt.missing || p.Stopped != tt.stopped {
+			t.Errorf("updateStatus(%s): missing %v, stopped %v, want %v, %v",
+				tt.asOf, p.Missing, p.Stopped, tt.missing, tt.stopped)
+		}
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"io"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 	"text/template"
 	"time"
@@ -13,18 +14,26 @@ import (
 
 func NewReport(cfg *Config) *Report {
 	return &Report{
-		cfg:  cfg,
-		data: NewReportData(cfg),
+		cfg:       cfg,
+		dedup:     newDedup(),
+		overrides: newOverridesUsage(),
+		data:      NewReportData(cfg),
 	}
 }
 
 type Report struct {
 	cfg *Config
 
-	fromDate time.Time
-	toDate   time.Time
+	fromDate  time.Time
+	toDate    time.Time
+	direction Direction
+	strict    bool
+	tag       string
+	dedup     *dedup
+	overrides *overridesUsage
 
-	data *ReportData
+	data      *ReportData
+	unmatched []Record
 }
 
 func (self *Report) WithFromDate(t time.Time) *Report {
@@ -37,53 +46,163 @@ func (self *Report) WithToDate(t time.Time) *Report {
 	return self
 }
 
-func (self *Report) Parse(file io.Reader) error {
-	parser, err := NewParser(file)
+func (self *Report) WithDirection(d Direction) *Report {
+	self.direction = d
+	return self
+}
+
+// WithStrict makes Parse fail on the first record, which doesn't match any
+// section. By default such records go into uncategorized section.
+func (self *Report) WithStrict(v bool) *Report {
+	self.strict = v
+	return self
+}
+
+// WithTag makes Parse skip records, which don't have given tag.
+func (self *Report) WithTag(tag string) *Report {
+	self.tag = tag
+	return self
+}
+
+func (self *Report) Parse(src Source) error {
+	err := self.Each(src, func(record Record) error {
+		m, err := self.cfg.MatchSection(record)
+		if err != nil {
+			return err
+		} else if !m.Found() && self.strict {
+			return fmt.Errorf("unknown record: %s", record.String())
+		} else if self.tag != "" && !slices.Contains(m.Tags, self.tag) {
+			return nil
+		} else if !m.Found() {
+			m = Match{}
+			m.Section, m.Key = self.uncategorized(record)
+		}
+		return self.data.addRecord(m, record)
+	})
 	if err != nil {
 		return err
 	}
+	self.data.finish()
+	return nil
+}
 
+// Each calls fn for every record from src, which passes configured direction
+// and dates. Records, which were seen in sources passed to previous calls, are
+// skipped. Overrides are applied to records and excluded records are skipped.
+func (self *Report) Each(src Source, fn func(record Record) error) error {
+	self.dedup.nextFile()
 	for {
-		record, err := parser.Next()
-		switch {
-		case err != nil:
+		record, err := src.Next()
+		if err != nil {
 			return err
-		case !record.Valid():
-			self.data.finish()
+		} else if !record.Valid() {
 			return nil
-		case !record.Out() || !record.Between(self.fromDate, self.toDate):
+		}
+
+		override := self.cfg.FindOverride(record)
+		self.overrides.seen(record, override)
+		switch {
+		case override != nil && override.Exclude:
+			continue
+		case !self.direction.Match(record) ||
+			!record.Between(self.fromDate, self.toDate):
 			continue
+		case self.dedup.duplicate(self.cfg.RecordKey(record)):
+			continue
+		case override != nil:
+			record = override.apply(record)
 		}
-		sectName, sectKey, err := self.cfg.FindSection(record)
-		if err != nil {
+		if err := fn(record); err != nil {
 			return err
-		} else if sectName == "" || sectKey == "" {
-			return fmt.Errorf("unknown record: line %d: %+v", record.Line(), record)
 		}
-		self.data.addRecord(sectName, sectKey, record)
 	}
 }
 
-func (self *Report) Print() error {
-	tmplPath, err := expandHomeDir(self.cfg.Template)
-	if err != nil {
-		return fmt.Errorf("expand home dir in %q: %w", self.cfg.Template, err)
-	} else if tmplPath == "" {
-		return errors.New("'template' not defined or expanded to empty string")
+// UnusedOverrides returns overrides, which didn't match any record. Overrides
+// with dates outside of dates of all read records aren't returned.
+func (self *Report) UnusedOverrides() []*Override {
+	return self.overrides.unused(self.cfg.Overrides())
+}
+
+func (self *Report) uncategorized(rec Record) (string, string) {
+	self.unmatched = append(self.unmatched, rec)
+	key := rec.Note()
+	if key == "" {
+		key = rec.AccountId()
+	}
+	return self.cfg.Uncategorized, key
+}
+
+// Unmatched returns records, which don't match any section and were added into
+// uncategorized section.
+func (self *Report) Unmatched() []Record {
+	return self.unmatched
+}
+
+// Print writes report to w in given output format.
+func (self *Report) Print(w io.Writer, output string) error {
+	switch output {
+	case "", OutputText:
+		return self.printTemplate(w)
+	case OutputJSON:
+		return self.data.WriteJSON(w)
+	case OutputCSV:
+		return self.data.WriteCSV(w)
+	case OutputXLSX:
+		return self.data.WriteXLSX(w)
+	case OutputHTML:
+		return self.data.WriteHTML(w)
+	case OutputMatrix:
+		return self.data.WriteMatrix(w)
 	}
+	return fmt.Errorf("unknown output %q, expected one of: %s", output,
+		strings.Join(Outputs, ", "))
+}
 
-	tmpl, err := template.ParseFiles(tmplPath)
+func (self *Report) printTemplate(w io.Writer) error {
+	tmpl, tmplPath, err := self.cfg.parseTemplate()
 	if err != nil {
-		return fmt.Errorf("parse template %q: %w", tmplPath, err)
+		return err
 	}
 
-	if err := tmpl.Execute(os.Stdout, self.data); err != nil {
+	if err := tmpl.Execute(w, self.data); err != nil {
 		return fmt.Errorf("exec %q: %w", tmplPath, err)
 	}
 
 	return nil
 }
 
+// parseTemplate returns parsed template of text report and its path.
+func (self *Config) parseTemplate() (*template.Template, string, error) {
+	tmplPath, err := expandHomeDir(self.Template)
+	if err != nil {
+		return nil, "", fmt.Errorf("expand home dir in %q: %w", self.Template, err)
+	} else if tmplPath == "" {
+		return nil, "", errors.New(
+			"'template' not defined or expanded to empty string")
+	}
+
+	tmpl, err := template.New(filepath.Base(tmplPath)).Funcs(templateFuncs).
+		ParseFiles(tmplPath)
+	if err != nil {
+		return nil, "", fmt.Errorf("parse template %q: %w", tmplPath, err)
+	}
+	return tmpl, tmplPath, nil
+}
+
+// templateFuncs are helpers for formatting in report templates.
+var templateFuncs = template.FuncMap{
+	// money formats money with thousands separators and currency:
+	// {{money .Money}}
+	"money": func(m Money) string { return m.Grouped() },
+	// amount formats money using given number of decimal digits, without
+	// currency: {{amount .Money 0}}
+	"amount": func(m Money, prec int) string { return m.decimal(prec) },
+	// indent returns two spaces for every level of subsections:
+	// {{indent .Level}}
+	"indent": func(level int) string { return strings.Repeat("  ", level) },
+}
+
 func expandHomeDir(path string) (string, error) {
 	tilda := "~" + string(os.PathSeparator)
 	if strings.HasPrefix(path, tilda) {
@@ -96,6 +215,11 @@ func expandHomeDir(path string) (string, error) {
 	return os.ExpandEnv(path), nil
 }
 
+// Duplicates returns number of skipped duplicate records.
+func (self *Report) Duplicates() int {
+	return self.dedup.skipped
+}
+
 func (self *Report) Data() *ReportData {
 	return self.data
 }
@@ -0,0 +1,48 @@
+package app
+
+import (
+	"strings"
+	"testing"
+)
+
+// testRecord returns valid record with given date, like 2026-03-02, amount in
+// hundredths of CZK and note.
+func testRecord(t *testing.T, line int, date string, amount int64, note string,
+) Record {
+	t.Helper()
+	return Record{
+		date:  testDate(t, date),
+		note:  note,
+		line:  line,
+		money: NewMoney(amount, "CZK"),
+		valid: true,
+	}
+}
+
+func TestReport_Parse_strictTag(t *testing.T) {
+	cfg := mustLoadConfig(t, `
+sections:
+  - name: Food
+    rules:
+      - re: '^Nákup: ALBERT,'
+        tags: [groceries]
+`)
+	records := []Record{
+		testRecord(t, 2, "2026-03-02", -25010, "Nákup: ALBERT, Praha 1, CZ"),
+		testRecord(t, 3, "2026-03-03", -10000, "Unknown shop"),
+	}
+
+	report := NewReport(cfg).WithTag("groceries")
+	if err := report.Parse(NewRecords(records)); err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if got := report.data.Count(); got != 1 {
+		t.Errorf("Count() = %d, want 1", got)
+	}
+
+	report = NewReport(cfg).WithTag("groceries").WithStrict(true)
+	err := report.Parse(NewRecords(records))
+	if err == nil || !strings.Contains(err.Error(), "unknown record: line 3") {
+		t.Errorf("Parse with strict and tag: error %v, want unknown record", err)
+	}
+}
@@ -4,6 +4,8 @@ import (
 	"bytes"
 	"fmt"
 	"regexp"
+	"regexp/syntax"
+	"slices"
 	"strings"
 	"text/template"
 	"text/template/parse"
@@ -15,10 +17,19 @@ type SectionRule struct {
 	Account string
 	Vs      string
 	If      string
-
-	keyTemplate *template.Template
-	reCompiled  *regexp.Regexp
-	ifTemplate  *template.Template
+	// Budget is a budget of payments matched by this rule.
+	Budget *BudgetConfig
+	// Tags are static tags or templates, like key, of payments matched by this
+	// rule.
+	Tags []string
+	// Split divides payments matched by this rule into parts in different
+	// sections.
+	Split []*SplitPart
+
+	keyTemplate  *template.Template
+	reCompiled   *regexp.Regexp
+	ifTemplate   *template.Template
+	tagTemplates []*template.Template
 }
 
 func (self *SectionRule) ExtractKey(rec Record) (string, error) {
@@ -39,6 +50,41 @@ func (self *SectionRule) ExtractKey(rec Record) (string, error) {
 	return self.accountKey(rec), nil
 }
 
+// Criteria returns names of configured criteria, which all must pass for
+// matching a record.
+func (self *SectionRule) Criteria() []string {
+	criteria := make([]string, 0, 4)
+	if self.Account != "" {
+		criteria = append(criteria, "account")
+		if self.Vs != "" {
+			criteria = append(criteria, "vs")
+		}
+	}
+	if self.Re != "" {
+		criteria = append(criteria, "re")
+	}
+	if self.If != "" {
+		criteria = append(criteria, "if")
+	}
+	if len(criteria) == 0 {
+		criteria = append(criteria, "key")
+	}
+	return criteria
+}
+
+// name returns short description of the rule, like its key or account.
+func (self *SectionRule) name() string {
+	switch {
+	case self.Key != "" && self.keyTemplate == nil:
+		return self.Key
+	case self.Account != "" && self.Vs != "":
+		return self.Account + ", VS: " + self.Vs
+	case self.Account != "":
+		return self.Account
+	}
+	return self.Re
+}
+
 func (self *SectionRule) knownAccount(rec Record) bool {
 	if self.Account != "" {
 		if self.Account != rec.AccountId() {
@@ -50,6 +96,104 @@ func (self *SectionRule) knownAccount(rec Record) bool {
 	return true
 }
 
+// covers reports whether the rule matches every record, which other rule
+// matches.
+func (self *SectionRule) covers(other *SectionRule) bool {
+	switch {
+	case !self.alwaysKeyed():
+		return false // depends on record
+	case self.Account != "" && self.Account != other.Account:
+		return false
+	case self.Account != "" && self.Vs != "" && self.Vs != other.Vs:
+		return false
+	case self.Re != "" && self.Re != other.Re:
+		return false
+	}
+	return true
+}
+
+// alwaysKeyed reports whether the rule extracts non-empty key from every
+// record, which passes its account and re. Otherwise the record falls through
+// to next rules, like with false if or empty capture group.
+func (self *SectionRule) alwaysKeyed() bool {
+	switch {
+	case self.If != "" || self.keyTemplate != nil:
+		return false
+	case self.Key != "":
+		return true
+	case self.reCompiled.NumSubexp() > 0:
+		return nonEmptyCapture(self.Re)
+	case self.Account != "":
+		return true
+	}
+	// key is note of the record
+	re, err := syntax.Parse(self.Re, syntax.Perl)
+	return err == nil && minLen(re) > 0
+}
+
+// nonEmptyCapture reports whether the first capture group of regexp matches
+// non-empty string every time the regexp matches.
+func nonEmptyCapture(expr string) bool {
+	re, err := syntax.Parse(expr, syntax.Perl)
+	if err != nil {
+		return false
+	}
+	capture, always := findCapture(re, 1)
+	return capture != nil && always && minLen(capture.Sub[0]) > 0
+}
+
+// findCapture returns capture group with given index and true, if it takes
+// part in every match of re.
+func findCapture(re *syntax.Regexp, idx int) (*syntax.Regexp, bool) {
+	switch re.Op {
+	case syntax.OpCapture:
+		if re.Cap == idx {
+			return re, true
+		}
+		return findCapture(re.Sub[0], idx)
+	case syntax.OpConcat, syntax.OpPlus, syntax.OpRepeat:
+		for _, sub := range re.Sub {
+			if capture, always := findCapture(sub, idx); capture != nil {
+				return capture, always && (re.Op != syntax.OpRepeat || re.Min > 0)
+			}
+		}
+	case syntax.OpStar, syntax.OpQuest, syntax.OpAlternate:
+		for _, sub := range re.Sub {
+			if capture, _ := findCapture(sub, idx); capture != nil {
+				return capture, false
+			}
+		}
+	}
+	return nil, false
+}
+
+// minLen returns minimal length in runes of strings, which re matches.
+func minLen(re *syntax.Regexp) int {
+	switch re.Op {
+	case syntax.OpLiteral:
+		return len(re.Rune)
+	case syntax.OpCharClass, syntax.OpAnyChar, syntax.OpAnyCharNotNL:
+		return 1
+	case syntax.OpCapture, syntax.OpPlus:
+		return minLen(re.Sub[0])
+	case syntax.OpRepeat:
+		return re.Min * minLen(re.Sub[0])
+	case syntax.OpConcat:
+		n := 0
+		for _, sub := range re.Sub {
+			n += minLen(sub)
+		}
+		return n
+	case syntax.OpAlternate:
+		n := minLen(re.Sub[0])
+		for _, sub := range re.Sub[1:] {
+			n = min(n, minLen(sub))
+		}
+		return n
+	}
+	return 0
+}
+
 func (self *SectionRule) templatedChecks(rec Record) (bool, string, error) {
 	if ok, err := self.templatedIf(rec); err != nil {
 		return false, "", err
@@ -111,7 +255,7 @@ func (self *SectionRule) templatedKey(rec Record) (string, error) {
 	}
 
 	var b bytes.Buffer
-	if err := self.keyTemplate.Execute(&b, &rec); err != nil {
+	if err := self.keyTemplate.Execute(&b, templateRecord{&rec}); err != nil {
 		return "", fmt.Errorf("extract rule key from %q: %w", self.Key, err)
 	}
 	return strings.Trim(b.String(), " "), nil
@@ -154,9 +298,42 @@ func (self *SectionRule) compileTemplates(sectName string, idx int) error {
 		}
 	}
 
+	self.tagTemplates = make([]*template.Template, len(self.Tags))
+	for i, tag := range self.Tags {
+		t, err := self.compileOneTemplate(tag)
+		if err != nil {
+			return fmt.Errorf("config: section %q, rule %d: tag: %w", sectName, idx,
+				err)
+		}
+		self.tagTemplates[i] = t
+	}
+
 	return nil
 }
 
+// ExtractTags returns tags of the record, matched by this rule. Empty tags,
+// produced by templates, are skipped.
+func (self *SectionRule) ExtractTags(rec Record) ([]string, error) {
+	if len(self.Tags) == 0 {
+		return nil, nil
+	}
+
+	tags := make([]string, 0, len(self.Tags))
+	for i, tag := range self.Tags {
+		if t := self.tagTemplates[i]; t != nil {
+			var b bytes.Buffer
+			if err := t.Execute(&b, templateRecord{&rec}); err != nil {
+				return nil, fmt.Errorf("extract tag from %q: %w", tag, err)
+			}
+			tag = strings.TrimSpace(b.String())
+		}
+		if tag != "" && !slices.Contains(tags, tag) {
+			tags = append(tags, tag)
+		}
+	}
+	return tags, nil
+}
+
 func (self *SectionRule) compileOneTemplate(tmpl string) (*template.Template, error) {
 	if tmpl == "" {
 		return nil, nil
@@ -185,10 +362,31 @@ func (self *SectionRule) templatedIf(rec Record) (bool, error) {
 	}
 
 	var b bytes.Buffer
-	if err := self.ifTemplate.Execute(&b, &rec); err != nil {
+	if err := self.ifTemplate.Execute(&b, templateRecord{&rec}); err != nil {
 		return false, fmt.Errorf("check rule if %q: %w", self.If, err)
 	}
 	s := strings.Trim(b.String(), " ")
 
 	return len(s) > 0, nil
 }
+
+// --------------------------------------------------
+
+// templateRecord is a record, as templates of rules see it.
+type templateRecord struct {
+	*Record
+}
+
+// Money returns absolute amount of the payment, which can be compared with
+// numbers, like {{if lt .Money 150.0}}.
+func (self templateRecord) Money() templateMoney {
+	return templateMoney(self.Record.Money().Float())
+}
+
+// templateMoney is amount of money in templates of rules. It keeps Float of
+// Money for templates, like {{if gt .Money.Float 1000.0}}.
+type templateMoney float64
+
+func (self templateMoney) Float() float64 {
+	return float64(self)
+}
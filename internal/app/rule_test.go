@@ -0,0 +1,32 @@
+package app
+
+import "testing"
+
+func TestSectionRule_ExtractKey_money(t *testing.T) {
+	tests := []struct {
+		key    string
+		amount int64
+		want   string
+	}{
+		{key: "{{if lt .Money 150.0}}cheap{{else}}food{{end}}", amount: -14999, want: "cheap"},
+		{key: "{{if lt .Money 150.0}}cheap{{else}}food{{end}}", amount: -15000, want: "food"},
+		{key: "{{if gt .Money.Float 1000.0}}big{{else}}small{{end}}", amount: -100001, want: "big"},
+		{key: "{{if gt .Money.Float 1000.0}}big{{else}}small{{end}}", amount: 100000, want: "small"},
+	}
+
+	for _, tt := range tests {
+		rule := &SectionRule{Key: tt.key}
+		if err := rule.Compile("test", 0); err != nil {
+			t.Fatal(err)
+		}
+
+		rec := Record{money: NewMoney(tt.amount, "CZK"), valid: true}
+		got, err := rule.ExtractKey(rec)
+		if err != nil {
+			t.Errorf("ExtractKey(%d) with %q: %v", tt.amount, tt.key, err)
+		} else if got != tt.want {
+			t.Errorf("ExtractKey(%d) with %q = %q, want %q", tt.amount, tt.key, got,
+				tt.want)
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package app
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+)
+
+// Formats of input files.
+const (
+	FormatCSV  = "csv"
+	FormatJSON = "json"
+	FormatXML  = "xml"
+)
+
+var Formats = []string{FormatCSV, FormatJSON, FormatXML}
+
+// Source is a source of records. Next returns invalid record at the end.
+type Source interface {
+	Next() (Record, error)
+}
+
+// NewSource returns source of records in given format. CSV is parsed using
+// csvFormat.
+func NewSource(r io.Reader, format string, csvFormat *CSVConfig,
+) (Source, error) {
+	switch format {
+	case "", FormatCSV:
+		return NewParser(r, csvFormat)
+	case FormatJSON:
+		return NewJSONParser(r)
+	case FormatXML:
+		return NewXMLParser(r)
+	}
+	return nil, fmt.Errorf("unknown format %q, expected one of: %s", format,
+		strings.Join(Formats, ", "))
+}
+
+// FormatByExt returns format of input file by its extension or empty string for
+// unknown extension.
+func FormatByExt(path string) string {
+	ext := strings.TrimPrefix(strings.ToLower(filepath.Ext(path)), ".")
+	for _, format := range Formats {
+		if ext == format {
+			return format
+		}
+	}
+	return ""
+}
+
+// ReadAll reads all records from src.
+func ReadAll(src Source) ([]Record, error) {
+	var records []Record
+	for {
+		rec, err := src.Next()
+		if err != nil {
+			return nil, err
+		} else if !rec.Valid() {
+			return records, nil
+		}
+		records = append(records, rec)
+	}
+}
+
+// NewRecords returns source of given records, like records from ReadAll.
+func NewRecords(records []Record) *Records {
+	return &Records{records: records}
+}
+
+type Records struct {
+	records []Record
+	next    int
+}
+
+func (self *Records) Next() (rec Record, err error) {
+	if self.next < len(self.records) {
+		rec = self.records[self.next]
+		self.next++
+	}
+	return
+}
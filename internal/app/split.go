@@ -0,0 +1,165 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"math/bits"
+)
+
+// SplitPart is a part of a payment, which goes into its own section and key.
+// Fixed amounts are taken first and money left is divided by weights of other
+// parts.
+type SplitPart struct {
+	// Section defaults to section of the rule and Key to key, extracted by the
+	// rule.
+	Section string
+	Key     string
+	Weight  int
+	// Amount is a fixed amount in reporting currency.
+	Amount string
+
+	amount Money
+}
+
+func (self *SplitPart) compile(cfg *Config, sect *SectionConfig) error {
+	if self.Section == "" {
+		self.Section = sect.Name
+	} else if partSect, ok := cfg.sectionIndex[self.Section]; !ok {
+		return fmt.Errorf("unknown section %q", self.Section)
+	} else if partSect.Income != sect.Income {
+		return fmt.Errorf("section %q has different direction", self.Section)
+	}
+
+	switch {
+	case self.Weight < 0:
+		return fmt.Errorf("negative weight %d", self.Weight)
+	case self.Amount != "" && self.Weight != 0:
+		return errors.New("both amount and weight defined")
+	case self.Amount != "":
+		m, err := parseConfigMoney(self.Amount, cfg.Currency)
+		if err != nil {
+			return err
+		} else if m.Negative() {
+			return fmt.Errorf("negative amount %q", self.Amount)
+		}
+		self.amount = m
+	case self.Weight == 0:
+		self.Weight = 1
+	}
+	return nil
+}
+
+func (self *Config) compileSplits() error {
+	return self.WalkSections(func(sect *SectionConfig) error {
+		for i, rule := range sect.Rules {
+			for j, part := range rule.Split {
+				if err := part.compile(self, sect); err != nil {
+					return fmt.Errorf("config: section %q, rule %d, split %d: %w",
+						sect.Name, i, j, err)
+				}
+			}
+		}
+		return nil
+	})
+}
+
+// --------------------------------------------------
+
+// matchPart is a part of money of a payment in its section and key.
+type matchPart struct {
+	section string
+	key     string
+	money   Money
+}
+
+// parts returns parts of money of matched payment. Without split it's one part
+// with all the money.
+func (self *Match) parts(money Money) []matchPart {
+	if len(self.split) == 0 {
+		return []matchPart{{self.Section, self.Key, money}}
+	}
+	return splitMoney(self.split, self.Section, self.Key, money)
+}
+
+// splitMoney divides money into parts. Money left after fixed amounts is
+// divided by weights, or goes into given section and key, if there are no
+// weighted parts. If fixed amounts exceed the money, they are reduced
+// proportionally and weighted parts get nothing.
+func splitMoney(split []*SplitPart, sectName, key string, money Money,
+) []matchPart {
+	parts := make([]matchPart, len(split), len(split)+1)
+	fixed := make([]int64, len(split))
+	weights := make([]int64, len(split))
+	var fixedSum, weightSum int64
+
+	for i, p := range split {
+		parts[i] = matchPart{section: p.Section, key: p.Key}
+		if parts[i].key == "" {
+			parts[i].key = key
+		}
+		parts[i].money = Money{currency: money.currency}
+
+		if p.Weight > 0 {
+			weights[i] = int64(p.Weight)
+			weightSum += weights[i]
+		} else {
+			fixed[i] = p.amount.amount
+			fixedSum += fixed[i]
+		}
+	}
+
+	if fixedSum >= money.amount {
+		for i, amount := range allocate(money.amount, fixed) {
+			parts[i].money.amount = amount
+		}
+		return parts
+	}
+
+	rest := money.amount - fixedSum
+	if weightSum == 0 {
+		for i := range parts {
+			parts[i].money.amount = fixed[i]
+		}
+		return append(parts, matchPart{sectName, key, Money{
+			amount: rest, currency: money.currency,
+		}})
+	}
+
+	for i, amount := range allocate(rest, weights) {
+		parts[i].money.amount = fixed[i] + amount
+	}
+	return parts
+}
+
+// allocate divides non-negative total by weights, which are zero for parts
+// getting nothing. Every part is floor of cumulative share minus previous
+// parts, so parts sum up to total, are never negative and parts with equal
+// weights differ by one at most.
+func allocate(total int64, weights []int64) []int64 {
+	var sum int64
+	for _, w := range weights {
+		sum += w
+	}
+
+	amounts := make([]int64, len(weights))
+	if sum == 0 {
+		return amounts
+	}
+
+	var cum, prev int64
+	for i, w := range weights {
+		cum += w
+		next := mulDiv(total, cum, sum)
+		amounts[i] = next - prev
+		prev = next
+	}
+	return amounts
+}
+
+// mulDiv returns floor of a*b/c for non-negative a, b and positive c, without
+// overflow of a*b.
+func mulDiv(a, b, c int64) int64 {
+	hi, lo := bits.Mul64(uint64(a), uint64(b))
+	q, _ := bits.Div64(hi, lo, uint64(c))
+	return int64(q)
+}
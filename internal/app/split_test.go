@@ -0,0 +1,125 @@
+package app
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestSplitMoney(t *testing.T) {
+	fixed := func(sect string, amount int64) *SplitPart {
+		return &SplitPart{Section: sect, amount: NewMoney(amount, "CZK")}
+	}
+	weighted := func(sect string, weight int) *SplitPart {
+		return &SplitPart{Section: sect, Weight: weight}
+	}
+
+	tests := []struct {
+		name  string
+		split []*SplitPart
+		money int64
+		want  []matchPart
+	}{
+		{
+			name:  "equal weights",
+			split: []*SplitPart{weighted("A", 1), weighted("B", 1), weighted("C", 1)},
+			money: 100,
+			want: []matchPart{
+				{"A", "key", NewMoney(33, "CZK")},
+				{"B", "key", NewMoney(33, "CZK")},
+				{"C", "key", NewMoney(34, "CZK")},
+			},
+		},
+		{
+			name: "rounding of many small parts",
+			split: []*SplitPart{
+				weighted("A", 1), weighted("B", 1), weighted("C", 1),
+				weighted("D", 1), weighted("E", 1), weighted("F", 1),
+			},
+			money: 5,
+			want: []matchPart{
+				{"A", "key", NewMoney(0, "CZK")},
+				{"B", "key", NewMoney(1, "CZK")},
+				{"C", "key", NewMoney(1, "CZK")},
+				{"D", "key", NewMoney(1, "CZK")},
+				{"E", "key", NewMoney(1, "CZK")},
+				{"F", "key", NewMoney(1, "CZK")},
+			},
+		},
+		{
+			name:  "different weights",
+			split: []*SplitPart{weighted("A", 2), weighted("B", 1)},
+			money: 100,
+			want: []matchPart{
+				{"A", "key", NewMoney(66, "CZK")},
+				{"B", "key", NewMoney(34, "CZK")},
+			},
+		},
+		{
+			name:  "fixed and weighted",
+			split: []*SplitPart{fixed("A", 1000), weighted("B", 1), weighted("C", 1)},
+			money: 2001,
+			want: []matchPart{
+				{"A", "key", NewMoney(1000, "CZK")},
+				{"B", "key", NewMoney(500, "CZK")},
+				{"C", "key", NewMoney(501, "CZK")},
+			},
+		},
+		{
+			name:  "fixed only",
+			split: []*SplitPart{fixed("A", 1000)},
+			money: 1500,
+			want: []matchPart{
+				{"A", "key", NewMoney(1000, "CZK")},
+				{"Rule", "key", NewMoney(500, "CZK")},
+			},
+		},
+		{
+			name:  "fixed only, exact",
+			split: []*SplitPart{fixed("A", 1000), fixed("B", 500)},
+			money: 1500,
+			want: []matchPart{
+				{"A", "key", NewMoney(1000, "CZK")},
+				{"B", "key", NewMoney(500, "CZK")},
+			},
+		},
+		{
+			name:  "fixed exceed money",
+			split: []*SplitPart{fixed("A", 1000), fixed("B", 500), weighted("C", 1)},
+			money: 900,
+			want: []matchPart{
+				{"A", "key", NewMoney(600, "CZK")},
+				{"B", "key", NewMoney(300, "CZK")},
+				{"C", "key", NewMoney(0, "CZK")},
+			},
+		},
+		{
+			name:  "own key",
+			split: []*SplitPart{{Section: "A", Key: "own", Weight: 1}, weighted("B", 1)},
+			money: 100,
+			want: []matchPart{
+				{"A", "own", NewMoney(50, "CZK")},
+				{"B", "key", NewMoney(50, "CZK")},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := splitMoney(tt.split, "Rule", "key", NewMoney(tt.money, "CZK"))
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("splitMoney(%d) = %v, want %v", tt.money, got, tt.want)
+			}
+
+			var sum int64
+			for _, part := range got {
+				if part.money.Negative() {
+					t.Errorf("negative part %v", part)
+				}
+				sum += part.money.amount
+			}
+			if sum != tt.money {
+				t.Errorf("sum of parts = %d, want %d", sum, tt.money)
+			}
+		})
+	}
+}
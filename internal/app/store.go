@@ -0,0 +1,164 @@
+package app
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+const storeFileName = "transactions.jsonl"
+
+func NewStore(cfg *Config) *Store {
+	return &Store{cfg: cfg, path: filepath.Join(cfg.Store, storeFileName)}
+}
+
+// Store is a local archive of records, kept as JSON lines in a file.
+type Store struct {
+	cfg  *Config
+	path string
+}
+
+func (self *Store) Path() string {
+	return self.path
+}
+
+// Import appends records from src, which aren't in the store yet, and returns
+// number of added and skipped records. Records are identified by
+// Config.RecordKey.
+func (self *Store) Import(src Source) (added, skipped int, err error) {
+	if self.cfg.Store == "" {
+		return 0, 0, errors.New("store not defined in config")
+	}
+
+	dedup := newDedup()
+	if err := self.loadKeys(dedup); err != nil {
+		return 0, 0, err
+	}
+
+	records, err := self.newRecords(src, dedup)
+	if err != nil || len(records) == 0 {
+		return 0, dedup.skipped, err
+	}
+
+	if err := self.append(records); err != nil {
+		return 0, 0, err
+	}
+	return len(records), dedup.skipped, nil
+}
+
+func (self *Store) loadKeys(dedup *dedup) error {
+	dedup.nextFile()
+	r, err := self.Open()
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	for {
+		rec, err := r.Next()
+		if err != nil {
+			return err
+		} else if !rec.Valid() {
+			return nil
+		}
+		dedup.duplicate(self.cfg.RecordKey(rec))
+	}
+}
+
+func (self *Store) newRecords(src Source, dedup *dedup) ([]Record, error) {
+	dedup.nextFile()
+	dedup.skipped = 0
+
+	var records []Record
+	for {
+		rec, err := src.Next()
+		if err != nil {
+			return nil, err
+		} else if !rec.Valid() {
+			return records, nil
+		} else if !dedup.duplicate(self.cfg.RecordKey(rec)) {
+			records = append(records, rec)
+		}
+	}
+}
+
+func (self *Store) append(records []Record) error {
+	if err := os.MkdirAll(filepath.Dir(self.path), 0o700); err != nil {
+		return fmt.Errorf("create store dir: %w", err)
+	}
+
+	file, err := os.OpenFile(self.path, os.O_WRONLY|os.O_CREATE|os.O_APPEND,
+		0o600)
+	if err != nil {
+		return fmt.Errorf("open store: %w", err)
+	}
+	defer file.Close()
+
+	w := bufio.NewWriter(file)
+	enc := json.NewEncoder(w)
+	for i := range records {
+		if err := enc.Encode(&records[i]); err != nil {
+			return fmt.Errorf("encode record: %w", err)
+		}
+	}
+
+	if err := w.Flush(); err != nil {
+		return fmt.Errorf("write store %q: %w", self.path, err)
+	} else if err := file.Close(); err != nil {
+		return fmt.Errorf("close store %q: %w", self.path, err)
+	}
+	return nil
+}
+
+// Open returns reader of all records from the store.
+func (self *Store) Open() (*StoreReader, error) {
+	if self.cfg.Store == "" {
+		return nil, errors.New("store not defined in config")
+	}
+
+	file, err := os.Open(self.path)
+	if err != nil {
+		return nil, fmt.Errorf("open store: %w", err)
+	}
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(nil, 1024*1024)
+	return &StoreReader{file: file, scanner: scanner}, nil
+}
+
+// --------------------------------------------------
+
+type StoreReader struct {
+	file    *os.File
+	scanner *bufio.Scanner
+	line    int
+}
+
+func (self *StoreReader) Next() (rec Record, err error) {
+	if !self.scanner.Scan() {
+		if err = self.scanner.Err(); err != nil {
+			err = fmt.Errorf("read store: %w", err)
+		}
+		return
+	}
+	self.line++
+
+	if err = json.Unmarshal(self.scanner.Bytes(), &rec); err != nil {
+		err = fmt.Errorf("decode store, line %d: %w", self.line, err)
+		return
+	}
+	rec.line = self.line
+	return
+}
+
+func (self *StoreReader) Close() error {
+	if err := self.file.Close(); err != nil {
+		return fmt.Errorf("close store: %w", err)
+	}
+	return nil
+}
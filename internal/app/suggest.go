@@ -0,0 +1,248 @@
+package app
+
+import (
+	"cmp"
+	"fmt"
+	"io"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"unicode"
+
+	"gopkg.in/yaml.v3"
+)
+
+// suggestExamples is a maximum number of example notes of a suggested rule.
+const suggestExamples = 3
+
+func NewSuggester(cfg *Config) *Suggester {
+	return &Suggester{
+		cfg:      cfg,
+		clusters: make(map[string]*Suggestion),
+	}
+}
+
+// Suggester clusters payments, which don't match any rule, by account, VS and
+// note prefix, and suggests rules for them.
+type Suggester struct {
+	cfg      *Config
+	clusters map[string]*Suggestion
+}
+
+// Add adds a payment into its cluster, if it doesn't match any rule.
+func (self *Suggester) Add(rec Record) error {
+	if m, err := self.cfg.MatchSection(rec); err != nil {
+		return err
+	} else if m.Found() {
+		return nil
+	}
+
+	money, err := self.cfg.Convert(rec.Money(), rec.Date())
+	if err != nil {
+		return fmt.Errorf("line %d: %w", rec.Line(), err)
+	}
+
+	var key string
+	rule := &SectionRule{}
+	if rec.AccountId() != "" {
+		key = "account:" + rec.AccountId() + "\x00" + rec.Vs()
+		rule.Account, rule.Vs = rec.AccountId(), rec.Vs()
+	} else if re := notePrefixRe(rec.Note()); re != "" {
+		key, rule.Re = "re:"+re, re
+	} else {
+		return nil
+	}
+
+	s := self.clusters[key]
+	if s == nil {
+		s = &Suggestion{Rule: rule}
+		self.clusters[key] = s
+	}
+	s.add(rec, money)
+	return nil
+}
+
+// notePrefix matches card payments, like "Nákup: MERCHANT, City, CZ".
+var notePrefix = regexp.MustCompile(`^([^:,]+:\s*)([^,]+),`)
+
+// NoteRe returns regexp for rule, which matches note and similar notes: its
+// normalized prefix or the whole note, if there is no prefix.
+func NoteRe(note string) string {
+	if re := notePrefixRe(note); re != "" {
+		return re
+	}
+	return "^" + regexp.QuoteMeta(note) + "$"
+}
+
+// notePrefixRe returns regexp, which matches normalized prefix of note: text
+// till first comma, or leading words without digits.
+func notePrefixRe(note string) string {
+	if m := notePrefix.FindStringSubmatch(note); m != nil {
+		return "^" + regexp.QuoteMeta(m[1]) + "(" +
+			regexp.QuoteMeta(strings.TrimSpace(m[2])) + ")" + spaceRe(m[2], false) +
+			","
+	} else if before, _, ok := strings.Cut(note, ","); ok &&
+		strings.TrimSpace(before) != "" {
+		return "^" + spaceRe(before, true) +
+			regexp.QuoteMeta(strings.TrimSpace(before)) + spaceRe(before, false) +
+			","
+	}
+
+	var words []string
+	for _, w := range strings.Fields(note) {
+		if strings.IndexFunc(w, unicode.IsDigit) >= 0 {
+			break
+		}
+		words = append(words, w)
+	}
+	if len(words) == 0 {
+		return ""
+	}
+	return "^" + regexp.QuoteMeta(strings.Join(words, " "))
+}
+
+// spaceRe returns regexp of spaces, if s has leading or trailing spaces, which
+// are trimmed from normalized prefix.
+func spaceRe(s string, leading bool) string {
+	trimmed := strings.TrimRightFunc(s, unicode.IsSpace)
+	if leading {
+		trimmed = strings.TrimLeftFunc(s, unicode.IsSpace)
+	}
+	if trimmed != s {
+		return `\s*`
+	}
+	return ""
+}
+
+// Suggestions returns suggested rules, largest total money first. Payments to
+// the same account are suggested by account and VS, if every VS repeats, and by
+// account only otherwise, like for VS with invoice numbers.
+func (self *Suggester) Suggestions() []*Suggestion {
+	suggestions := make([]*Suggestion, 0, len(self.clusters))
+	byAccount := make(map[string][]*Suggestion)
+	for _, s := range self.clusters {
+		if s.Rule.Account != "" {
+			byAccount[s.Rule.Account] = append(byAccount[s.Rule.Account], s)
+		} else {
+			suggestions = append(suggestions, s)
+		}
+	}
+
+	for account, clusters := range byAccount {
+		if !mergeVs(clusters) {
+			suggestions = append(suggestions, clusters...)
+			continue
+		}
+		// the same order of examples every time
+		sort.Slice(clusters, func(i, j int) bool {
+			return clusters[i].Rule.Vs < clusters[j].Rule.Vs
+		})
+		merged := &Suggestion{Rule: &SectionRule{Account: account}}
+		for _, s := range clusters {
+			merged.merge(s)
+		}
+		suggestions = append(suggestions, merged)
+	}
+
+	sort.Slice(suggestions, func(i, j int) bool {
+		if c := suggestions[i].Money.Cmp(suggestions[j].Money); c != 0 {
+			return c > 0
+		} else if suggestions[i].Count != suggestions[j].Count {
+			return suggestions[i].Count > suggestions[j].Count
+		}
+		r1, r2 := suggestions[i].Rule, suggestions[j].Rule
+		return cmp.Or(cmp.Compare(r1.Account, r2.Account),
+			cmp.Compare(r1.Vs, r2.Vs), cmp.Compare(r1.Re, r2.Re)) < 0
+	})
+	return suggestions
+}
+
+// mergeVs returns true, if clusters of the same account must be suggested as
+// one rule by account: some payments have no VS or VS doesn't repeat.
+func mergeVs(clusters []*Suggestion) bool {
+	if len(clusters) == 1 {
+		return clusters[0].Rule.Vs == ""
+	}
+	for _, s := range clusters {
+		if s.Rule.Vs == "" || s.Count < 2 {
+			return true
+		}
+	}
+	return false
+}
+
+// --------------------------------------------------
+
+// Suggestion is a suggested rule for a cluster of payments.
+type Suggestion struct {
+	Rule     *SectionRule
+	Money    Money
+	Count    int
+	Examples []string
+}
+
+func (self *Suggestion) add(rec Record, money Money) {
+	self.Money = self.Money.Add(money)
+	self.Count++
+	self.addExample(rec.Note())
+}
+
+// merge adds payments of other cluster.
+func (self *Suggestion) merge(other *Suggestion) {
+	self.Money = self.Money.Add(other.Money)
+	self.Count += other.Count
+	for _, note := range other.Examples {
+		self.addExample(note)
+	}
+}
+
+func (self *Suggestion) addExample(note string) {
+	if len(self.Examples) < suggestExamples && note != "" &&
+		!slices.Contains(self.Examples, note) {
+		self.Examples = append(self.Examples, note)
+	}
+}
+
+// WriteSuggestions writes suggested rules as YAML, ready for pasting into rules
+// of a section. Every rule has a comment with its payments.
+func WriteSuggestions(w io.Writer, suggestions []*Suggestion) error {
+	rules := &yaml.Node{Kind: yaml.SequenceNode}
+	for _, s := range suggestions {
+		rule := &yaml.Node{Kind: yaml.MappingNode}
+		if err := rule.Encode(s.Rule); err != nil {
+			return fmt.Errorf("encode rule: %w", err)
+		}
+		rule.Content = withoutEmptyValues(rule.Content)
+
+		comment := fmt.Sprintf("%d payments, %s", s.Count, s.Money.Grouped())
+		if len(s.Examples) > 0 {
+			comment += ": " + strings.Join(s.Examples, "; ")
+		}
+		rule.HeadComment = comment
+		rules.Content = append(rules.Content, rule)
+	}
+
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2)
+	if err := enc.Encode(rules); err != nil {
+		return fmt.Errorf("encode suggestions: %w", err)
+	}
+	return enc.Close()
+}
+
+// withoutEmptyValues returns key and value nodes of mapping without empty
+// values.
+func withoutEmptyValues(content []*yaml.Node) []*yaml.Node {
+	nonEmpty := content[:0]
+	for i := 0; i+1 < len(content); i += 2 {
+		v := content[i+1]
+		if v.Kind == yaml.ScalarNode && v.Value == "" ||
+			v.Kind == yaml.SequenceNode && len(v.Content) == 0 ||
+			v.Tag == "!!null" {
+			continue
+		}
+		nonEmpty = append(nonEmpty, content[i], v)
+	}
+	return nonEmpty
+}
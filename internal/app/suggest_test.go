@@ -0,0 +1,221 @@
+package app
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestNotePrefixRe(t *testing.T) {
+	tests := []struct {
+		note  string
+		want  string
+		match []string
+	}{
+		{
+			note:  "Nákup: ALBERT, Praha 1, CZ",
+			want:  `^Nákup: (ALBERT),`,
+			match: []string{"Nákup: ALBERT, Brno, CZ"},
+		},
+		{
+			note:  "Nákup:  DM drogerie markt , Praha, CZ",
+			want:  `^Nákup:  (DM drogerie markt)\s*,`,
+			match: []string{"Nákup:  DM drogerie markt, Brno, CZ"},
+		},
+		{
+			note:  "Platba kartou, 2026-03-02",
+			want:  `^Platba kartou,`,
+			match: []string{"Platba kartou, 2026-04-02"},
+		},
+		{
+			note:  " Platba kartou , 2026-03-02",
+			want:  `^\s*Platba kartou\s*,`,
+			match: []string{"Platba kartou, 2026-04-02"},
+		},
+		{
+			note:  "Rent for 03/2026",
+			want:  `^Rent for`,
+			match: []string{"Rent for 04/2026"},
+		},
+		{
+			note:  "Cost (a+b) 12",
+			want:  `^Cost \(a\+b\)`,
+			match: []string{"Cost (a+b) 13"},
+		},
+		{note: "2026-03-02"},
+		{note: ""},
+	}
+
+	for _, tt := range tests {
+		got := notePrefixRe(tt.note)
+		if got != tt.want {
+			t.Errorf("notePrefixRe(%q) = %q, want %q", tt.note, got, tt.want)
+			continue
+		} else if got == "" {
+			continue
+		}
+
+		re := regexp.MustCompile(got)
+		for _, s := range append(tt.match, tt.note) {
+			if !re.MatchString(s) {
+				t.Errorf("%q doesn't match %q", got, s)
+			}
+		}
+	}
+}
+
+func TestNoteRe(t *testing.T) {
+	if got, want := NoteRe("2026-03-02 (1)"), `^2026-03-02 \(1\)$`; got != want {
+		t.Errorf("NoteRe() = %q, want %q", got, want)
+	}
+}
+
+func TestWithoutEmptyValues(t *testing.T) {
+	var node yaml.Node
+	err := yaml.Unmarshal([]byte(`
+a: ""
+b: x
+c: []
+d: null
+e: [y]
+f:
+`), &node)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var keys []string
+	content := withoutEmptyValues(node.Content[0].Content)
+	for i := 0; i < len(content); i += 2 {
+		keys = append(keys, content[i].Value)
+	}
+	if want := []string{"b", "e"}; !slices.Equal(keys, want) {
+		t.Errorf("withoutEmptyValues() keys = %q, want %q", keys, want)
+	}
+}
+
+const testSuggestConfig = `
+sections:
+  - name: Food
+    rules:
+      - re: '^Nákup: LIDL,'
+`
+
+// testSuggestRecords returns records with index of their suggestion.
+func testSuggestRecords(t *testing.T) ([]Record, []int) {
+	payments := []struct {
+		amount     int64
+		account    string
+		vs         string
+		note       string
+		suggestion int
+	}{
+		{-200000, "222222/0100", "5", "Shop", 0},
+		{-200000, "222222/0100", "6", "Shop", 0},
+		{-150000, "111111/0100", "1001", "Electricity", 1},
+		{-150000, "111111/0100", "1001", "Electricity", 1},
+		{-50000, "111111/0100", "1002", "Gas", 2},
+		{-50000, "111111/0100", "1002", "Gas", 2},
+		{-30000, "777777/0300", "42", "Gym", 3},
+		{-30000, "777777/0300", "42", "Gym", 3},
+		{-30000, "777777/0300", "42", "Gym", 3},
+		{-25010, "", "", "Nákup: ALBERT, Praha 1, CZ", 4},
+		{-10000, "", "", "Nákup: ALBERT, Brno, CZ", 4},
+		{-10000, "", "", "Nákup: LIDL, Praha, CZ", -1},
+	}
+
+	records := make([]Record, len(payments))
+	suggestions := make([]int, len(payments))
+	for i, p := range payments {
+		records[i] = testRecord(t, i+1, "2026-03-02", p.amount, p.note)
+		records[i].accountId, records[i].vs = p.account, p.vs
+		suggestions[i] = p.suggestion
+	}
+	return records, suggestions
+}
+
+func TestSuggester(t *testing.T) {
+	cfg := mustLoadConfig(t, testSuggestConfig)
+	records, _ := testSuggestRecords(t)
+	suggester := NewSuggester(cfg)
+	for _, rec := range records {
+		if err := suggester.Add(rec); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := WriteSuggestions(&buf, suggester.Suggestions()); err != nil {
+		t.Fatal(err)
+	}
+
+	want := `# 2 payments, 4 000.00 CZK: Shop
+- account: 222222/0100
+# 2 payments, 3 000.00 CZK: Electricity
+- account: 111111/0100
+  vs: "1001"
+# 2 payments, 1 000.00 CZK: Gas
+- account: 111111/0100
+  vs: "1002"
+# 3 payments, 900.00 CZK: Gym
+- account: 777777/0300
+  vs: "42"
+# 2 payments, 350.10 CZK: Nákup: ALBERT, Praha 1, CZ; Nákup: ALBERT, Brno, CZ
+- re: '^Nákup: (ALBERT),'
+`
+	if got := buf.String(); got != want {
+		t.Errorf("WriteSuggestions() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestSuggester_roundTrip(t *testing.T) {
+	cfg := mustLoadConfig(t, testSuggestConfig)
+	records, want := testSuggestRecords(t)
+	suggester := NewSuggester(cfg)
+	for _, rec := range records {
+		if err := suggester.Add(rec); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// every suggested rule in its own section
+	var b strings.Builder
+	b.WriteString(testSuggestConfig)
+	for i, s := range suggester.Suggestions() {
+		var buf bytes.Buffer
+		if err := WriteSuggestions(&buf, []*Suggestion{s}); err != nil {
+			t.Fatal(err)
+		}
+		fmt.Fprintf(&b, "  - name: S%d\n    rules:\n", i)
+		for _, line := range strings.SplitAfter(buf.String(), "\n") {
+			if line != "" {
+				b.WriteString("      " + line)
+			}
+		}
+	}
+
+	cfg, err := loadTestConfig(t, b.String())
+	if err != nil {
+		t.Fatalf("LoadConfig of suggested rules: %v\n%s", err, b.String())
+	}
+
+	for i, rec := range records {
+		m, err := cfg.MatchSection(rec)
+		if err != nil {
+			t.Fatal(err)
+		}
+		wantSect := "Food"
+		if want[i] >= 0 {
+			wantSect = fmt.Sprintf("S%d", want[i])
+		}
+		if m.Section != wantSect {
+			t.Errorf("%s: matched section %q, want %q", rec.String(), m.Section,
+				wantSect)
+		}
+	}
+}